@@ -2,16 +2,29 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"log"
+	"log/slog"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"gocloudcamp_test/internal/database"
 	"gocloudcamp_test/internal/handlers"
+	"gocloudcamp_test/internal/kafka"
+	"gocloudcamp_test/internal/redissync"
+	"gocloudcamp_test/internal/rpc"
 	"gocloudcamp_test/internal/server"
 	"gocloudcamp_test/internal/service"
+	"gocloudcamp_test/internal/tracing"
 )
 
 func main() {
+	migrateOnly := flag.Bool("migrate", false, "apply database migrations and exit")
+	flag.Parse()
+
 	serviceCtx, cancel := context.WithCancel(context.Background())
 
 	uri := fmt.Sprintf(
@@ -23,25 +36,212 @@ func main() {
 		os.Getenv("POSTGRES_PORT"),
 	)
 
+	if *migrateOnly {
+		database.Connect(serviceCtx, uri)
+		cancel()
+
+		return
+	}
+
 	addr := fmt.Sprintf(
 		"0.0.0.0:%s",
 		os.Getenv("SERVICE_PORT"),
 	)
 
+	tracerProvider, tracerShutdown := tracing.Setup(serviceCtx, os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"))
+
+	config := service.Config{
+		ProgressInterval: envDuration("PROGRESS_INTERVAL", time.Second),
+		TickInterval:     envDuration("TICK_INTERVAL", time.Second),
+		PersistInterval:  envDuration("PERSIST_INTERVAL", time.Second*5),
+		ResumeOnStart:    envBool("RESUME_ON_START", false),
+		ErrorLogBuffer:   envInt("ERROR_LOG_BUFFER", 64),
+		MaxBodySize:      int64(envInt("MAX_BODY_SIZE", 1<<20)),
+		UniqueNames:      envBool("UNIQUE_NAMES", false),
+		IdempotencyTTL:   envDuration("IDEMPOTENCY_TTL", time.Hour*24),
+		AuthEnabled:      envBool("AUTH_ENABLED", false),
+		AuthSecret:       os.Getenv("JWT_SECRET"),
+		CorsOrigins:      envList("CORS_ORIGINS"),
+		RequestTimeout:   envDuration("REQUEST_TIMEOUT", time.Second*10),
+		MaxLaunches:      envInt("MAX_LAUNCHES", 0),
+		ImportDuration:   uint(envInt("IMPORT_DEFAULT_DURATION", 180)),
+		HistorySize:      envInt("HISTORY_SIZE", 100),
+		SoftDelete:       envBool("SOFT_DELETE", false),
+		DeleteRetention:  envDuration("DELETE_RETENTION", time.Hour*24*30),
+		RequireIfMatch:   envBool("REQUIRE_IF_MATCH", true),
+		RateLimit:        envFloat("RATE_LIMIT", 0),
+		RateBurst:        envInt("RATE_BURST", 20),
+		TrustedProxies:   envList("TRUSTED_PROXIES"),
+		WriteConcurrency: envInt("WRITE_CONCURRENCY", 0),
+		WriteQueueWait:   envDuration("WRITE_QUEUE_WAIT", time.Millisecond*500),
+		PprofEnabled:     envBool("PPROF_ENABLED", false),
+		TracerProvider:   tracerProvider,
+		Logger:           slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: envLevel("LOG_LEVEL", slog.LevelInfo)})),
+	}
+
+	if config.AuthEnabled && config.AuthSecret == "" {
+		log.Fatal("config | AUTH_ENABLED requires JWT_SECRET")
+	}
+
+	var producer *kafka.Producer
+
+	if brokers := envList("KAFKA_BROKERS"); len(brokers) > 0 {
+		topic := os.Getenv("KAFKA_TOPIC")
+		if topic == "" {
+			topic = "playlist-events"
+		}
+
+		producer = kafka.New(brokers, topic)
+		config.Publisher = producer
+	}
+
+	var syncer *redissync.Syncer
+
+	if redisAddr := os.Getenv("REDIS_ADDR"); redisAddr != "" {
+		prefix := os.Getenv("REDIS_SYNC_PREFIX")
+		if prefix == "" {
+			prefix = "playlist-sync"
+		}
+
+		syncer = redissync.New(redisAddr, prefix)
+		config.Syncer = syncer
+		config.NodeId = os.Getenv("NODE_ID")
+	}
+
 	database := database.Connect(serviceCtx, uri)
-	service := service.New(database)
+	service := service.New(database, config)
 	handlers := handlers.New(serviceCtx, service)
 	server := server.New(addr, handlers)
 
-	service.Start()
+	var rpcServer *rpc.Server
+
+	if grpcPort := os.Getenv("GRPC_PORT"); grpcPort != "" {
+		rpcServer = rpc.New(serviceCtx, fmt.Sprintf("0.0.0.0:%s", grpcPort), service)
+	}
+
+	service.Init(serviceCtx)
+
+	go service.RunSync(serviceCtx)
 
 	go server.Run()
-	go func() {
-		defer cancel()
-		server.GracefulShutdown(serviceCtx, service.ChanForceStop)
-	}()
+
+	if rpcServer != nil {
+		go rpcServer.Run()
+	}
 
 	go service.ForceStop(cancel)
 
-	service.Stop(serviceCtx)
+	server.GracefulShutdown(serviceCtx, service.ChanForceStop)
+
+	if rpcServer != nil {
+		rpcServer.GracefulStop()
+	}
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(serviceCtx, time.Second*5)
+	defer shutdownCancel()
+
+	if err := service.Shutdown(shutdownCtx); err != nil {
+		log.Printf("service | error | %v", err)
+	}
+
+	if syncer != nil {
+		if err := syncer.Close(); err != nil {
+			log.Printf("redis | error | %v", err)
+		}
+	}
+
+	if producer != nil {
+		if err := producer.Close(); err != nil {
+			log.Printf("kafka | error | %v", err)
+		}
+	}
+
+	if err := tracerShutdown(shutdownCtx); err != nil {
+		log.Printf("tracing | error | %v", err)
+	}
+
+	cancel()
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Fatalf("config | invalid %s | %s", key, value)
+	}
+
+	return d
+}
+
+func envBool(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Fatalf("config | invalid %s | %s", key, value)
+	}
+
+	return b
+}
+
+func envInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil || i < 0 {
+		log.Fatalf("config | invalid %s | %s", key, value)
+	}
+
+	return i
+}
+
+func envFloat(key string, fallback float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f < 0 {
+		log.Fatalf("config | invalid %s | %s", key, value)
+	}
+
+	return f
+}
+
+func envList(key string) []string {
+	var list []string
+
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
+
+func envLevel(key string, fallback slog.Level) slog.Level {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	var level slog.Level
+
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		log.Fatalf("config | invalid %s | %s", key, value)
+	}
+
+	return level
 }
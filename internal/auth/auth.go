@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var (
+	ErrMissingToken = errors.New("authorization token is missing")
+	ErrInvalidToken = errors.New("authorization token is invalid")
+	ErrNotBearer    = errors.New("authorization scheme must be Bearer")
+)
+
+type Claims struct {
+	UserId string `json:"user_id,omitempty"`
+	Admin  bool   `json:"admin,omitempty"`
+	jwt.RegisteredClaims
+}
+
+type contextKey struct{}
+
+func Parse(secret []byte, token string) (*Claims, error) {
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+
+	claims := &Claims{}
+
+	parsed, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		return secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !parsed.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	if claims.UserId == "" {
+		claims.UserId = claims.Subject
+	}
+
+	if claims.UserId == "" {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+func FromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(contextKey{}).(*Claims)
+
+	return claims, ok
+}
@@ -0,0 +1,22 @@
+package build
+
+import "runtime"
+
+var (
+	Version = "dev"
+	Commit  = "unknown"
+)
+
+type Info struct {
+	Version   string `json:"version" xml:"version"`
+	Commit    string `json:"commit" xml:"commit"`
+	GoVersion string `json:"go_version" xml:"go_version"`
+}
+
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		GoVersion: runtime.Version(),
+	}
+}
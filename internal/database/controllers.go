@@ -1,9 +1,31 @@
 package database
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
 )
 
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+type SongError struct {
+	Index int
+	Err   error
+}
+
+func (e *SongError) Error() string {
+	return fmt.Sprintf("song %d: %v", e.Index, e.Err)
+}
+
+func (e *SongError) Unwrap() error {
+	return e.Err
+}
+
 func (db *Database) LoadPlaylists() ([]Playlist, error) {
 	log.Print("database | load playlists")
 
@@ -14,30 +36,216 @@ func (db *Database) LoadPlaylists() ([]Playlist, error) {
 	return pls, err
 }
 
-func (db *Database) CreatePlaylist(pl *Playlist) error {
-	err := db.Create(&pl).Error
+func (db *Database) SearchPlaylists(query string) ([]Playlist, error) {
+	log.Printf("database | search playlists | query %s", query)
+
+	var pls []Playlist
+
+	pattern := "%" + likeEscaper.Replace(query) + "%"
+
+	err := db.Where("name ILIKE ?", pattern).Order("id asc").Find(&pls).Error
+
+	return pls, err
+}
+
+func (db *Database) SearchSongs(ctx context.Context, query string, limit int, owner string, restricted bool) ([]Song, error) {
+	log.Printf("database | search songs | query %s | limit %d", query, limit)
+
+	var sns []Song
+
+	pattern := "%" + likeEscaper.Replace(query) + "%"
+
+	tx := db.WithContext(ctx).
+		Joins("JOIN playlists ON playlists.id = songs.playlist_id AND playlists.deleted_at IS NULL").
+		Where("songs.name ILIKE ?", pattern)
+
+	if restricted {
+		tx = tx.Where("playlists.owner_id = ?", owner)
+	}
+
+	err := tx.Order("songs.playlist_id asc, songs.position asc, songs.song_id asc").Limit(limit).Find(&sns).Error
+
+	return sns, err
+}
+
+type Stats struct {
+	Playlists     int64
+	Songs         int64
+	TotalDuration uint64
+}
+
+func (db *Database) Stats(ctx context.Context, owner string, restricted bool) (Stats, error) {
+	log.Print("database | stats")
+
+	var st Stats
+
+	pls := db.WithContext(ctx).Model(&Playlist{})
+	sns := db.WithContext(ctx).Model(&Song{}).Joins("JOIN playlists ON playlists.id = songs.playlist_id AND playlists.deleted_at IS NULL")
+
+	if restricted {
+		pls = pls.Where("owner_id = ?", owner)
+		sns = sns.Where("playlists.owner_id = ?", owner)
+	}
+
+	if err := pls.Count(&st.Playlists).Error; err != nil {
+		return st, err
+	}
+
+	err := sns.Select("COUNT(*) AS songs, COALESCE(SUM(songs.duration), 0) AS total_duration").Scan(&st).Error
+
+	return st, err
+}
+
+func (db *Database) CreatePlaylist(ctx context.Context, pl *Playlist) error {
+	err := db.WithContext(ctx).Create(&pl).Error
 
 	log.Printf("database | create playlist | id %d", pl.Id)
 
-	return err
+	return translateError(err)
+}
+
+func createPlaylistWithSongs(tx *gorm.DB, pl *Playlist, sns []Song) error {
+	if err := tx.Create(pl).Error; err != nil {
+		return err
+	}
+
+	for i := range sns {
+		sns[i].SongId = 0
+		sns[i].PlaylistId = pl.Id
+		sns[i].Position = i
+
+		if err := tx.Create(&sns[i]).Error; err != nil {
+			return &SongError{Index: i, Err: err}
+		}
+	}
+
+	return nil
+}
+
+func (db *Database) CreatePlaylistWithSongs(ctx context.Context, pl *Playlist, sns []Song) error {
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createPlaylistWithSongs(tx, pl, sns)
+	})
+
+	log.Printf("database | create playlist with songs | id %d | count %d", pl.Id, len(sns))
+
+	return translateError(err)
+}
+
+func (db *Database) CreatePlaylistsWithSongs(ctx context.Context, pls []Playlist, sns [][]Song) error {
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range pls {
+			if err := createPlaylistWithSongs(tx, &pls[i], sns[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	log.Printf("database | create playlists with songs | count %d", len(pls))
+
+	return translateError(err)
+}
+
+func (db *Database) UpdatePlaylist(ctx context.Context, id uint, name string) error {
+	log.Printf("database | update playlist | id %d", id)
+
+	return translateError(db.WithContext(ctx).Model(&Playlist{Id: id}).Updates(map[string]any{
+		"name":    name,
+		"version": gorm.Expr("version + 1"),
+	}).Error)
+}
+
+func (db *Database) SavePlayback(id uint, sid uint, elapsed uint, state string) error {
+	log.Printf("database | save playback | id %d | songid %d | elapsed %d | state %s", id, sid, elapsed, state)
+
+	return db.Model(&Playlist{Id: id}).UpdateColumns(map[string]any{
+		"current_song_id": sid,
+		"elapsed":         elapsed,
+		"state":           state,
+	}).Error
+}
+
+func (db *Database) AddPlayCounts(plays map[uint]uint) error {
+	log.Printf("database | add play counts | count %d", len(plays))
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for id, count := range plays {
+			if err := tx.Model(&Song{SongId: id}).UpdateColumn("play_count", gorm.Expr("play_count + ?", count)).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (db *Database) SetShareToken(ctx context.Context, id uint, token string) error {
+	log.Printf("database | set share token | id %d", id)
+
+	return db.WithContext(ctx).Model(&Playlist{Id: id}).Update("share_token", token).Error
+}
+
+func (db *Database) SetWebhook(ctx context.Context, id uint, url string) error {
+	log.Printf("database | set webhook | id %d", id)
+
+	return db.WithContext(ctx).Model(&Playlist{Id: id}).UpdateColumns(map[string]any{
+		"webhook_url": url,
+		"version":     gorm.Expr("version + 1"),
+	}).Error
+}
+
+func (db *Database) BumpVersion(ctx context.Context, id uint) error {
+	log.Printf("database | bump version | id %d", id)
+
+	return db.WithContext(ctx).Model(&Playlist{Id: id}).UpdateColumn("version", gorm.Expr("version + 1")).Error
 }
 
-func (db *Database) UpdatePlaylist(id uint, name string) error {
-	pl := Playlist{Id: id}
+func (db *Database) SetVolume(ctx context.Context, id uint, volume uint) error {
+	log.Printf("database | set volume | id %d | volume %d", id, volume)
 
-	db.First(&pl)
+	return db.WithContext(ctx).Model(&Playlist{Id: id}).UpdateColumns(map[string]any{
+		"volume":  volume,
+		"version": gorm.Expr("version + 1"),
+	}).Error
+}
 
-	pl.Name = name
+func (db *Database) SoftDeletePlaylist(ctx context.Context, id uint) error {
+	log.Printf("database | soft delete playlist | id %d", id)
+
+	return db.WithContext(ctx).Delete(&Playlist{}, id).Error
+}
 
-	log.Printf("database | update playlist | id %d", pl.Id)
+func (db *Database) PurgePlaylist(ctx context.Context, id uint) error {
+	log.Printf("database | purge playlist | id %d", id)
 
-	return db.Save(&pl).Error
+	return translateError(db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("playlist_id = ?", id).Delete(&Song{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Delete(&Playlist{}, id).Error
+	}))
 }
 
-func (db *Database) DeletePlaylist(id uint) error {
-	log.Printf("database | delete playlist | id %d", id)
+func (db *Database) DeletedPlaylist(ctx context.Context, id uint, since time.Time) (Playlist, error) {
+	log.Printf("database | deleted playlist | id %d", id)
+
+	var pl Playlist
 
-	return db.Delete(&Playlist{}, id).Error
+	err := db.WithContext(ctx).Unscoped().Where("id = ? AND deleted_at IS NOT NULL AND deleted_at > ?", id, since).Take(&pl).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return pl, ErrNotFound
+	}
+
+	return pl, err
+}
+
+func (db *Database) RestorePlaylist(ctx context.Context, id uint) error {
+	log.Printf("database | restore playlist | id %d", id)
+
+	return db.WithContext(ctx).Unscoped().Model(&Playlist{Id: id}).UpdateColumn("deleted_at", nil).Error
 }
 
 func (db *Database) LoadSongs() ([]Song, error) {
@@ -45,34 +253,226 @@ func (db *Database) LoadSongs() ([]Song, error) {
 
 	var sns []Song
 
-	err := db.Order("song_id asc").Find(&sns).Error
+	err := db.Where("playlist_id IN (?)", db.Model(&Playlist{}).Select("id")).Order("position asc, song_id asc").Find(&sns).Error
+
+	return sns, err
+}
+
+func (db *Database) LoadPlaylistSongs(id uint) ([]Song, error) {
+	log.Printf("database | load playlist songs | id %d", id)
+
+	var sns []Song
+
+	err := db.Where("playlist_id = ?", id).Order("position asc, song_id asc").Find(&sns).Error
 
 	return sns, err
 }
 
-func (db *Database) CreateSong(sn *Song) error {
-	err := db.Create(&sn).Error
+func nextPosition(tx *gorm.DB, id uint) (int, error) {
+	var position int
+
+	err := tx.Model(&Song{}).
+		Select("COALESCE(MAX(position) + 1, 0)").
+		Where("playlist_id = ?", id).
+		Scan(&position).Error
+
+	return position, err
+}
+
+func (db *Database) CreateSong(ctx context.Context, sn *Song) error {
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		position, err := nextPosition(tx, sn.PlaylistId)
+		if err != nil {
+			return err
+		}
+
+		sn.Position = position
+
+		if err := tx.Create(&sn).Error; err != nil {
+			return err
+		}
+
+		return touchPlaylist(tx, sn.PlaylistId)
+	})
 
 	log.Printf("database | create song | id %d", sn.SongId)
 
 	return err
 }
 
-func (db *Database) UpdateSong(id uint, name string, duration uint) error {
-	sn := Song{SongId: id}
+func (db *Database) InsertSong(ctx context.Context, sn *Song, position int) error {
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+
+		if err := tx.Model(&Song{}).Where("playlist_id = ?", sn.PlaylistId).Order("position").Pluck("song_id", &ids).Error; err != nil {
+			return err
+		}
+
+		if position < 0 || position > len(ids) {
+			position = len(ids)
+		}
+
+		sn.Position = position
+
+		if err := tx.Create(&sn).Error; err != nil {
+			return err
+		}
+
+		for i, id := range ids[position:] {
+			if err := tx.Model(&Song{}).Where("song_id = ?", id).Update("position", position+i+1).Error; err != nil {
+				return err
+			}
+		}
+
+		return touchPlaylist(tx, sn.PlaylistId)
+	})
+
+	log.Printf("database | insert song | id %d | position %d", sn.SongId, position)
+
+	return err
+}
+
+func (db *Database) InsertSongs(ctx context.Context, id uint, sns []Song, position int) error {
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+
+		if err := tx.Model(&Song{}).Where("playlist_id = ?", id).Order("position").Pluck("song_id", &ids).Error; err != nil {
+			return err
+		}
+
+		if position < 0 || position > len(ids) {
+			position = len(ids)
+		}
+
+		for i := range sns {
+			sns[i].SongId = 0
+			sns[i].PlaylistId = id
+			sns[i].Position = position + i
+
+			if err := tx.Create(&sns[i]).Error; err != nil {
+				return &SongError{Index: i, Err: err}
+			}
+		}
+
+		for i, sid := range ids[position:] {
+			if err := tx.Model(&Song{}).Where("song_id = ?", sid).Update("position", position+len(sns)+i).Error; err != nil {
+				return err
+			}
+		}
+
+		return touchPlaylist(tx, id)
+	})
+
+	log.Printf("database | insert songs | playlist id %d | count %d | position %d", id, len(sns), position)
+
+	return err
+}
+
+func (db *Database) UpdateSong(ctx context.Context, id uint, sid uint, name string, duration uint, tags []string) error {
+	log.Printf("database | update song | id %d", sid)
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&Song{SongId: sid}).Updates(map[string]any{
+			"name":     name,
+			"duration": Duration(duration),
+			"tags":     NormalizeTags(tags),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return touchPlaylist(tx, id)
+	})
+}
+
+func touchPlaylist(tx *gorm.DB, id uint) error {
+	return tx.Model(&Playlist{Id: id}).UpdateColumns(map[string]any{
+		"updated_at": time.Now(),
+		"version":    gorm.Expr("version + 1"),
+	}).Error
+}
+
+func (db *Database) DeleteSong(ctx context.Context, id uint, sid uint) error {
+	log.Printf("database | delete song | id %d", sid)
 
-	db.First(&sn)
+	return translateError(db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&Song{}, sid).Error; err != nil {
+			return err
+		}
 
-	sn.Name = name
-	sn.Duration = duration
+		return touchPlaylist(tx, id)
+	}))
+}
 
-	log.Printf("database | update song | id %d", sn.SongId)
+func (db *Database) SetFavorite(ctx context.Context, id uint, sid uint, favorite bool) error {
+	log.Printf("database | set favorite | id %d | favorite %t", sid, favorite)
 
-	return db.Save(&sn).Error
+	return translateError(db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Song{SongId: sid}).UpdateColumn("favorite", favorite).Error; err != nil {
+			return err
+		}
+
+		return touchPlaylist(tx, id)
+	}))
 }
 
-func (db *Database) DeleteSong(id uint) error {
-	log.Printf("database | delete song | id %d", id)
+func (db *Database) UpdateSongPositions(ctx context.Context, id uint, ids []uint) error {
+	log.Printf("database | update song positions | playlist id %d | count %d", id, len(ids))
+
+	return translateError(db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, sid := range ids {
+			if err := tx.Model(&Song{}).Where("song_id = ?", sid).Update("position", i).Error; err != nil {
+				return err
+			}
+		}
+
+		return touchPlaylist(tx, id)
+	}))
+}
+
+func (db *Database) ReplaceSongs(ctx context.Context, id uint, sns []Song) error {
+	log.Printf("database | replace songs | playlist id %d | count %d", id, len(sns))
+
+	return translateError(db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("playlist_id = ?", id).Delete(&Song{}).Error; err != nil {
+			return err
+		}
+
+		for i := range sns {
+			sns[i].SongId = 0
+			sns[i].PlaylistId = id
+			sns[i].Position = i
+
+			if err := tx.Create(&sns[i]).Error; err != nil {
+				return err
+			}
+		}
+
+		return touchPlaylist(tx, id)
+	}))
+}
+
+func (db *Database) TransferSong(ctx context.Context, id uint, sid uint, target uint) error {
+	log.Printf("database | transfer song | id %d | target %d", sid, target)
+
+	return translateError(db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		position, err := nextPosition(tx, target)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&Song{}).Where("song_id = ?", sid).Updates(map[string]any{
+			"playlist_id": target,
+			"position":    position,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := touchPlaylist(tx, id); err != nil {
+			return err
+		}
 
-	return db.Delete(&Song{}, id).Error
+		return touchPlaylist(tx, target)
+	}))
 }
@@ -0,0 +1,86 @@
+package database_test
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"gocloudcamp_test/internal/database"
+	"gocloudcamp_test/internal/database/dbtest"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+
+	os.Exit(m.Run())
+}
+
+func countVersionBumps(queries []string) int {
+	count := 0
+
+	for _, query := range queries {
+		if strings.HasPrefix(query, `UPDATE "playlists"`) && strings.Contains(query, `"version"=version + 1`) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func TestMutationsTouchPlaylist(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		bumps  int
+		mutate func(db *database.Database) error
+	}{
+		{"update playlist", 1, func(db *database.Database) error {
+			return db.UpdatePlaylist(ctx, 1, "renamed")
+		}},
+		{"set volume", 1, func(db *database.Database) error {
+			return db.SetVolume(ctx, 1, 50)
+		}},
+		{"create song", 1, func(db *database.Database) error {
+			return db.CreateSong(ctx, &database.Song{PlaylistId: 1, Name: "song", Duration: 10})
+		}},
+		{"insert songs", 1, func(db *database.Database) error {
+			return db.InsertSongs(ctx, 1, []database.Song{{Name: "song", Duration: 10}}, 0)
+		}},
+		{"update song", 1, func(db *database.Database) error {
+			return db.UpdateSong(ctx, 1, 2, "renamed", 10, nil)
+		}},
+		{"delete song", 1, func(db *database.Database) error {
+			return db.DeleteSong(ctx, 1, 2)
+		}},
+		{"set favorite", 1, func(db *database.Database) error {
+			return db.SetFavorite(ctx, 1, 2, true)
+		}},
+		{"update song positions", 1, func(db *database.Database) error {
+			return db.UpdateSongPositions(ctx, 1, []uint{3, 2})
+		}},
+		{"replace songs", 1, func(db *database.Database) error {
+			return db.ReplaceSongs(ctx, 1, []database.Song{{Name: "song", Duration: 10}})
+		}},
+		{"transfer song", 2, func(db *database.Database) error {
+			return db.TransferSong(ctx, 1, 2, 3)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, store := dbtest.Open(t)
+
+			if err := tt.mutate(db); err != nil {
+				t.Fatal(err)
+			}
+
+			if bumps := countVersionBumps(store.Queries()); bumps != tt.bumps {
+				t.Fatalf("%d version bumps, want %d", bumps, tt.bumps)
+			}
+		})
+	}
+}
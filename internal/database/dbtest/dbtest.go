@@ -0,0 +1,301 @@
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gocloudcamp_test/internal/database"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type Store struct {
+	mu      sync.Mutex
+	seq     int64
+	queries []string
+	fail    func(query string) error
+	stall   func(query string) bool
+}
+
+const stallLimit = time.Second * 5
+
+func Open(tb testing.TB) (*database.Database, *Store) {
+	tb.Helper()
+
+	store := &Store{}
+
+	sqlDB := sql.OpenDB(connector{store})
+	tb.Cleanup(func() { sqlDB.Close() })
+
+	db, err := database.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		tb.Fatalf("dbtest | open | %v", err)
+	}
+
+	return db, store
+}
+
+func (st *Store) Fail(fn func(query string) error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.fail = fn
+}
+
+func (st *Store) Stall(fn func(query string) bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.stall = fn
+}
+
+func (st *Store) Queries() []string {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	return append([]string(nil), st.queries...)
+}
+
+func (st *Store) Reset() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.queries = nil
+}
+
+func (st *Store) wait(ctx context.Context, query string) error {
+	st.mu.Lock()
+	stall := st.stall != nil && st.stall(query)
+	st.mu.Unlock()
+
+	if !stall {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(stallLimit):
+		return driver.ErrBadConn
+	}
+}
+
+func (st *Store) run(query string) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.fail != nil {
+		if err := st.fail(query); err != nil {
+			return err
+		}
+	}
+
+	st.queries = append(st.queries, query)
+
+	return nil
+}
+
+func (st *Store) next() int64 {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.seq++
+
+	return st.seq
+}
+
+type connector struct {
+	store *Store
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{c.store}, nil
+}
+
+func (c connector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, driver.ErrSkip
+}
+
+type conn struct {
+	store *Store
+}
+
+func (c *conn) Prepare(string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, _ driver.TxOptions) (driver.Tx, error) {
+	if err := c.store.wait(ctx, "BEGIN"); err != nil {
+		return nil, err
+	}
+
+	if err := c.store.run("BEGIN"); err != nil {
+		return nil, err
+	}
+
+	return tx{c.store}, nil
+}
+
+func (c *conn) Ping(context.Context) error {
+	return c.store.run("PING")
+}
+
+func (c *conn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if err := c.store.wait(ctx, query); err != nil {
+		return nil, err
+	}
+
+	if err := c.store.run(query); err != nil {
+		return nil, err
+	}
+
+	return driver.RowsAffected(1), nil
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if err := c.store.wait(ctx, query); err != nil {
+		return nil, err
+	}
+
+	if err := c.store.run(query); err != nil {
+		return nil, err
+	}
+
+	columns := returning(query)
+	if columns == nil {
+		return &rows{}, nil
+	}
+
+	values := make([][]driver.Value, insertedRows(query))
+
+	for i := range values {
+		id := c.store.next()
+
+		values[i] = make([]driver.Value, len(columns))
+
+		for j, column := range columns {
+			if strings.HasSuffix(column, "_at") {
+				values[i][j] = time.Now()
+			} else {
+				values[i][j] = id
+			}
+		}
+	}
+
+	return &rows{columns: columns, values: values}, nil
+}
+
+type tx struct {
+	store *Store
+}
+
+func (t tx) Commit() error {
+	return t.store.run("COMMIT")
+}
+
+func (t tx) Rollback() error {
+	return t.store.run("ROLLBACK")
+}
+
+type rows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *rows) Columns() []string {
+	return r.columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+
+	return nil
+}
+
+func returning(query string) []string {
+	if !strings.HasPrefix(query, "INSERT") {
+		return nil
+	}
+
+	i := strings.LastIndex(query, " RETURNING ")
+	if i < 0 {
+		return nil
+	}
+
+	var columns []string
+
+	for _, column := range strings.Split(query[i+len(" RETURNING "):], ",") {
+		columns = append(columns, strings.Trim(strings.TrimSpace(column), `"`))
+	}
+
+	return columns
+}
+
+func insertedRows(query string) int {
+	i := strings.Index(query, " VALUES ")
+	if i < 0 {
+		return 1
+	}
+
+	values := query[i:]
+
+	for _, clause := range []string{" ON CONFLICT ", " RETURNING "} {
+		if j := strings.Index(values, clause); j >= 0 {
+			values = values[:j]
+		}
+	}
+
+	count, depth := 0, 0
+
+	for _, c := range values {
+		switch c {
+		case '(':
+			if depth == 0 {
+				count++
+			}
+
+			depth++
+		case ')':
+			depth--
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var ErrDurationFormat = errors.New("duration must be a number of seconds or a mm:ss string")
+
+type Duration uint
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var seconds uint
+
+	if err := json.Unmarshal(data, &seconds); err == nil {
+		*d = Duration(seconds)
+
+		return nil
+	}
+
+	var text string
+
+	if err := json.Unmarshal(data, &text); err != nil {
+		return ErrDurationFormat
+	}
+
+	seconds, err := ParseDuration(text)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(seconds)
+
+	return nil
+}
+
+func ParseDuration(text string) (uint, error) {
+	parts := strings.Split(text, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, ErrDurationFormat
+	}
+
+	var seconds uint64
+
+	for i, part := range parts {
+		value, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return 0, ErrDurationFormat
+		}
+
+		if i > 0 && value > 59 {
+			return 0, ErrDurationFormat
+		}
+
+		seconds = seconds*60 + value
+	}
+
+	return uint(seconds), nil
+}
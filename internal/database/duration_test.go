@@ -0,0 +1,51 @@
+package database_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"gocloudcamp_test/internal/database"
+)
+
+func TestDurationUnmarshal(t *testing.T) {
+	tests := []struct {
+		in   string
+		want database.Duration
+		err  error
+	}{
+		{`225`, 225, nil},
+		{`"3:45"`, 225, nil},
+		{`"03:45"`, 225, nil},
+		{`"1:02:03"`, 3723, nil},
+		{`"0:00"`, 0, nil},
+		{`"90:00"`, 5400, nil},
+		{`"3:60"`, 0, database.ErrDurationFormat},
+		{`"1:60:00"`, 0, database.ErrDurationFormat},
+		{`"345"`, 0, database.ErrDurationFormat},
+		{`"3:4x"`, 0, database.ErrDurationFormat},
+		{`"3:"`, 0, database.ErrDurationFormat},
+		{`":45"`, 0, database.ErrDurationFormat},
+		{`"-3:45"`, 0, database.ErrDurationFormat},
+		{`"1:2:3:4"`, 0, database.ErrDurationFormat},
+		{`""`, 0, database.ErrDurationFormat},
+		{`-1`, 0, database.ErrDurationFormat},
+		{`1.5`, 0, database.ErrDurationFormat},
+		{`true`, 0, database.ErrDurationFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var d database.Duration
+
+			err := json.Unmarshal([]byte(tt.in), &d)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err %v, want %v", err, tt.err)
+			}
+
+			if d != tt.want {
+				t.Fatalf("duration %d, want %d", d, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const migrationLock = 7243001
+
+var ErrMigrationName = errors.New("migration file name must start with a version number")
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+type migration struct {
+	version uint64
+	name    string
+	sql     string
+}
+
+func loadMigrations() ([]migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	var ms []migration
+
+	for _, entry := range entries {
+		prefix, _, _ := strings.Cut(entry.Name(), "_")
+
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrMigrationName, entry.Name())
+		}
+
+		body, err := fs.ReadFile(migrationFiles, path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		ms = append(ms, migration{version: version, name: entry.Name(), sql: string(body)})
+	}
+
+	sort.Slice(ms, func(i, j int) bool { return ms[i].version < ms[j].version })
+
+	return ms, nil
+}
+
+func (db *Database) Migrate() (int, error) {
+	ms, err := loadMigrations()
+	if err != nil {
+		return 0, err
+	}
+
+	err = db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version bigint PRIMARY KEY,
+		name text NOT NULL,
+		applied_at timestamptz NOT NULL DEFAULT now()
+	)`).Error
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+
+	for _, m := range ms {
+		ok, err := db.applyMigration(m)
+		if err != nil {
+			return applied, fmt.Errorf("migration %s: %w", m.name, err)
+		}
+
+		if ok {
+			applied++
+		}
+	}
+
+	log.Printf("database | migrate | applied %d | total %d", applied, len(ms))
+
+	return applied, nil
+}
+
+func (db *Database) applyMigration(m migration) (bool, error) {
+	applied := false
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLock).Error; err != nil {
+			return err
+		}
+
+		var count int64
+
+		if err := tx.Table("schema_migrations").Where("version = ?", m.version).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count > 0 {
+			return nil
+		}
+
+		if err := tx.Exec(m.sql).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec("INSERT INTO schema_migrations (version, name) VALUES (?, ?)", m.version, m.name).Error; err != nil {
+			return err
+		}
+
+		applied = true
+
+		log.Printf("database | migration applied | version %d | %s", m.version, m.name)
+
+		return nil
+	})
+
+	return applied, err
+}
@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS playlists (
+    id bigserial PRIMARY KEY
+);
+
+ALTER TABLE playlists
+    ADD COLUMN IF NOT EXISTS name text DEFAULT 'playlist',
+    ADD COLUMN IF NOT EXISTS current_song_id bigint,
+    ADD COLUMN IF NOT EXISTS elapsed bigint,
+    ADD COLUMN IF NOT EXISTS state text DEFAULT 'stopped',
+    ADD COLUMN IF NOT EXISTS owner_id text,
+    ADD COLUMN IF NOT EXISTS share_token text,
+    ADD COLUMN IF NOT EXISTS webhook_url text,
+    ADD COLUMN IF NOT EXISTS volume bigint DEFAULT 100,
+    ADD COLUMN IF NOT EXISTS version bigint DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS created_at timestamptz DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS updated_at timestamptz DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+
+CREATE INDEX IF NOT EXISTS idx_playlists_owner_id ON playlists (owner_id);
+CREATE INDEX IF NOT EXISTS idx_playlists_share_token ON playlists (share_token);
+CREATE INDEX IF NOT EXISTS idx_playlists_deleted_at ON playlists (deleted_at);
+
+CREATE TABLE IF NOT EXISTS songs (
+    song_id bigserial PRIMARY KEY
+);
+
+ALTER TABLE songs
+    ADD COLUMN IF NOT EXISTS playlist_id bigint,
+    ADD COLUMN IF NOT EXISTS name text DEFAULT 'song',
+    ADD COLUMN IF NOT EXISTS duration bigint DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS tags text DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS position bigint,
+    ADD COLUMN IF NOT EXISTS play_count bigint,
+    ADD COLUMN IF NOT EXISTS favorite boolean,
+    ADD COLUMN IF NOT EXISTS created_at timestamptz DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS updated_at timestamptz DEFAULT now();
@@ -1,13 +1,42 @@
 package database
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
 type Playlist struct {
-	Id   uint   `json:",omitempty" gorm:"primarykey"`
-	Name string `json:",omitempty" gorm:"default:playlist"`
+	Id            uint           `json:",omitempty" gorm:"primarykey"`
+	Name          string         `json:",omitempty" gorm:"default:playlist"`
+	CurrentSongId uint           `json:"-"`
+	Elapsed       uint           `json:"-"`
+	State         string         `json:"-" gorm:"default:stopped"`
+	OwnerId       string         `json:"-" gorm:"index"`
+	ShareToken    string         `json:"-" gorm:"index"`
+	WebhookUrl    string         `json:"-"`
+	Volume        *uint          `json:"-" gorm:"default:100"`
+	Version       uint           `json:"-" gorm:"default:1"`
+	CreatedAt     time.Time      `json:"-" gorm:"default:now()"`
+	UpdatedAt     time.Time      `json:"-" gorm:"default:now()"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 type Song struct {
-	SongId     uint   `json:",omitempty" gorm:"primarykey"`
-	PlaylistId uint   `json:",omitempty"`
-	Name       string `json:",omitempty" gorm:"default:song"`
-	Duration   uint   `json:",omitempty" gorm:"default:1"`
+	SongId     uint      `json:",omitempty" gorm:"primarykey"`
+	PlaylistId uint      `json:",omitempty"`
+	Name       string    `json:",omitempty" gorm:"default:song"`
+	Duration   Duration  `json:",omitempty" gorm:"default:1"`
+	Tags       Tags      `json:",omitempty" gorm:"type:text;default:'[]'"`
+	Position   int       `json:"-"`
+	PlayCount  uint      `json:"-"`
+	Favorite   bool      `json:"-"`
+	CreatedAt  time.Time `json:"-" gorm:"default:now()"`
+	UpdatedAt  time.Time `json:"-" gorm:"default:now()"`
+}
+
+func (sn *Song) BeforeSave(tx *gorm.DB) error {
+	sn.Tags = NormalizeTags(sn.Tags)
+
+	return nil
 }
@@ -2,12 +2,28 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"net"
+	"strings"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrDuplicateName = errors.New("playlist with this name already exists")
+	ErrNotFound      = errors.New("record not found")
+	ErrUnavailable   = errors.New("database is unavailable")
+)
+
+const uniqueNameIndex = "idx_playlists_name_lower"
+
 type Database struct {
 	*gorm.DB
 }
@@ -15,14 +31,113 @@ type Database struct {
 func Connect(ctx context.Context, uri string) *Database {
 	log.Printf("database | connecting | %s", uri)
 
-	db, err := gorm.Open(postgres.Open(uri))
+	db, err := Open(postgres.Open(uri))
 	if err != nil {
 		log.Fatalf("database | %v", err)
 	}
 
-	db.AutoMigrate(&Playlist{}, &Song{})
-
 	log.Print("database | connected")
 
-	return &Database{db.WithContext(ctx)}
+	database := &Database{db.WithContext(ctx)}
+
+	if _, err := database.Migrate(); err != nil {
+		log.Fatalf("database | migrate | %v", err)
+	}
+
+	return database
+}
+
+func Open(dialector gorm.Dialector, opts ...gorm.Option) (*Database, error) {
+	db, err := gorm.Open(dialector, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := registerCallbacks(db); err != nil {
+		return nil, err
+	}
+
+	return &Database{db}, nil
+}
+
+func (db *Database) Ping(ctx context.Context) error {
+	sqlDB, err := db.DB.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.PingContext(ctx)
+}
+
+func (db *Database) SetUniqueNames(enabled bool) error {
+	log.Printf("database | unique names | %t", enabled)
+
+	if !enabled {
+		return db.Exec("DROP INDEX IF EXISTS " + uniqueNameIndex).Error
+	}
+
+	return db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS " + uniqueNameIndex + " ON playlists (lower(name)) WHERE deleted_at IS NULL").Error
+}
+
+func (db *Database) Transaction(fc func(tx *gorm.DB) error, opts ...*sql.TxOptions) error {
+	return translateError(db.DB.Transaction(fc, opts...))
+}
+
+func registerCallbacks(db *gorm.DB) error {
+	translate := func(tx *gorm.DB) {
+		if tx.Error != nil {
+			tx.Error = translateError(tx.Error)
+		}
+	}
+
+	callbacks := db.Callback()
+
+	for _, err := range []error{
+		callbacks.Create().Register("player:translate_error", translate),
+		callbacks.Query().Register("player:translate_error", translate),
+		callbacks.Update().Register("player:translate_error", translate),
+		callbacks.Delete().Register("player:translate_error", translate),
+		callbacks.Row().Register("player:translate_error", translate),
+		callbacks.Raw().Register("player:translate_error", translate),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func translateError(err error) error {
+	var pgErr *pgconn.PgError
+
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == uniqueNameIndex {
+		return ErrDuplicateName
+	}
+
+	if isConnectionError(err) && !errors.Is(err, ErrUnavailable) {
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+
+	return err
+}
+
+func isConnectionError(err error) bool {
+	var pgErr *pgconn.PgError
+
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "53300", "57P01", "57P02", "57P03":
+			return true
+		}
+
+		return strings.HasPrefix(pgErr.Code, "08")
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) ||
+		errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
 }
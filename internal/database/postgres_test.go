@@ -0,0 +1,77 @@
+package database_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"gocloudcamp_test/internal/database"
+	"gocloudcamp_test/internal/database/dbtest"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestTranslateError(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		err         error
+		unavailable bool
+		duplicate   bool
+	}{
+		{"bad connection", driver.ErrBadConn, true, false},
+		{"unexpected eof", io.ErrUnexpectedEOF, true, false},
+		{"connection failure", &pgconn.PgError{Code: "08006"}, true, false},
+		{"too many connections", &pgconn.PgError{Code: "53300"}, true, false},
+		{"admin shutdown", &pgconn.PgError{Code: "57P01"}, true, false},
+		{"unique name", &pgconn.PgError{Code: "23505", ConstraintName: "idx_playlists_name_lower"}, false, true},
+		{"other unique constraint", &pgconn.PgError{Code: "23505", ConstraintName: "songs_pkey"}, false, false},
+		{"foreign key", &pgconn.PgError{Code: "23503"}, false, false},
+		{"logic error", errors.New("logic error"), false, false},
+	}
+
+	mutations := []struct {
+		name   string
+		mutate func(db *database.Database) error
+	}{
+		{"update", func(db *database.Database) error { return db.UpdatePlaylist(ctx, 1, "renamed") }},
+		{"create", func(db *database.Database) error {
+			return db.CreateSong(ctx, &database.Song{PlaylistId: 1, Name: "song", Duration: 10})
+		}},
+		{"transaction", func(db *database.Database) error {
+			return db.CreatePlaylistWithSongs(ctx, &database.Playlist{Name: "playlist"}, []database.Song{{Duration: 10}})
+		}},
+	}
+
+	for _, tt := range tests {
+		for _, m := range mutations {
+			t.Run(tt.name+" "+m.name, func(t *testing.T) {
+				db, store := dbtest.Open(t)
+
+				store.Fail(func(query string) error {
+					if query == "BEGIN" || query == "COMMIT" || query == "ROLLBACK" {
+						return nil
+					}
+
+					return tt.err
+				})
+
+				err := m.mutate(db)
+				if err == nil {
+					t.Fatal("mutation succeeded on a failing database")
+				}
+
+				if got := errors.Is(err, database.ErrUnavailable); got != tt.unavailable {
+					t.Fatalf("err %v unavailable %t, want %t", err, got, tt.unavailable)
+				}
+
+				if got := errors.Is(err, database.ErrDuplicateName); got != tt.duplicate {
+					t.Fatalf("err %v duplicate %t, want %t", err, got, tt.duplicate)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+var ErrTagsFormat = errors.New("tags column must contain a json array")
+
+type Tags []string
+
+func NormalizeTags(tags []string) Tags {
+	var normalized Tags
+
+	seen := map[string]bool{}
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+
+		if tag == "" || seen[tag] {
+			continue
+		}
+
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	return normalized
+}
+
+func (t Tags) Has(tag string) bool {
+	for _, own := range t {
+		if own == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t Tags) Value() (driver.Value, error) {
+	if t == nil {
+		return "[]", nil
+	}
+
+	b, err := json.Marshal([]string(t))
+
+	return string(b), err
+}
+
+func (t *Tags) Scan(value any) error {
+	var data []byte
+
+	switch v := value.(type) {
+	case nil:
+		*t = nil
+
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return ErrTagsFormat
+	}
+
+	var tags []string
+
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return ErrTagsFormat
+	}
+
+	*t = NormalizeTags(tags)
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package database_test
+
+import (
+	"reflect"
+	"testing"
+
+	"gocloudcamp_test/internal/database"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []string
+		want database.Tags
+	}{
+		{"nil", nil, nil},
+		{"empty", []string{}, nil},
+		{"blank", []string{"", "  "}, nil},
+		{"lowercased and trimmed", []string{" Rock ", "JAZZ"}, database.Tags{"rock", "jazz"}},
+		{"duplicates", []string{"rock", "Rock", " rock"}, database.Tags{"rock"}},
+		{"order kept", []string{"b", "a", "c"}, database.Tags{"b", "a", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := database.NormalizeTags(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("tags %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"gocloudcamp_test/internal/database"
+)
+
+const BackupVersion = 1
+
+var ErrBackupVersion = errors.New("unsupported backup version")
+
+type Backup struct {
+	Version   int              `json:"version"`
+	Playlists []BackupPlaylist `json:"playlists"`
+}
+
+type BackupPlaylist struct {
+	Name      string       `json:"name"`
+	Owner     string       `json:"owner,omitempty"`
+	Volume    *uint        `json:"volume,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+	Songs     []BackupSong `json:"songs"`
+}
+
+type BackupSong struct {
+	Name     string            `json:"name"`
+	Duration database.Duration `json:"duration"`
+	Tags     database.Tags     `json:"tags,omitempty"`
+	Favorite bool              `json:"favorite,omitempty"`
+}
+
+type BackupWriter struct {
+	w     io.Writer
+	enc   *json.Encoder
+	count int
+}
+
+func NewBackupWriter(w io.Writer) (*BackupWriter, error) {
+	if _, err := fmt.Fprintf(w, `{"version":%d,"playlists":[`, BackupVersion); err != nil {
+		return nil, err
+	}
+
+	return &BackupWriter{w: w, enc: json.NewEncoder(w)}, nil
+}
+
+func (bw *BackupWriter) WritePlaylist(pl database.Playlist, sns []database.Song) error {
+	if bw.count > 0 {
+		if _, err := io.WriteString(bw.w, ","); err != nil {
+			return err
+		}
+	}
+
+	bpl := BackupPlaylist{
+		Name:      pl.Name,
+		Owner:     pl.OwnerId,
+		Volume:    pl.Volume,
+		CreatedAt: pl.CreatedAt,
+		Songs:     make([]BackupSong, 0, len(sns)),
+	}
+
+	for _, sn := range sns {
+		bpl.Songs = append(bpl.Songs, BackupSong{Name: sn.Name, Duration: sn.Duration, Tags: sn.Tags, Favorite: sn.Favorite})
+	}
+
+	bw.count++
+
+	return bw.enc.Encode(bpl)
+}
+
+func (bw *BackupWriter) Close() error {
+	_, err := io.WriteString(bw.w, "]}\n")
+
+	return err
+}
@@ -0,0 +1,108 @@
+package export_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gocloudcamp_test/internal/database"
+	"gocloudcamp_test/internal/export"
+)
+
+func TestBackupWriter(t *testing.T) {
+	volume := uint(40)
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	type entry struct {
+		pl  database.Playlist
+		sns []database.Song
+	}
+
+	tests := []struct {
+		name    string
+		entries []entry
+		want    []export.BackupPlaylist
+	}{
+		{"empty", nil, []export.BackupPlaylist{}},
+		{"one playlist", []entry{
+			{database.Playlist{Name: "first", OwnerId: "alice", Volume: &volume, CreatedAt: created}, []database.Song{
+				{Name: "intro", Duration: 225, Tags: database.Tags{"pop"}, Favorite: true},
+				{Name: "outro", Duration: 60},
+			}},
+		}, []export.BackupPlaylist{
+			{Name: "first", Owner: "alice", Volume: &volume, CreatedAt: created, Songs: []export.BackupSong{
+				{Name: "intro", Duration: 225, Tags: database.Tags{"pop"}, Favorite: true},
+				{Name: "outro", Duration: 60},
+			}},
+		}},
+		{"several playlists", []entry{
+			{database.Playlist{Name: "first", CreatedAt: created}, []database.Song{{Name: "intro", Duration: 225}}},
+			{database.Playlist{Name: "empty", CreatedAt: created}, nil},
+			{database.Playlist{Name: "last", CreatedAt: created}, []database.Song{{Name: "outro", Duration: 60}}},
+		}, []export.BackupPlaylist{
+			{Name: "first", CreatedAt: created, Songs: []export.BackupSong{{Name: "intro", Duration: 225}}},
+			{Name: "empty", CreatedAt: created, Songs: []export.BackupSong{}},
+			{Name: "last", CreatedAt: created, Songs: []export.BackupSong{{Name: "outro", Duration: 60}}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf strings.Builder
+
+			bw, err := export.NewBackupWriter(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, e := range tt.entries {
+				if err := bw.WritePlaylist(e.pl, e.sns); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := bw.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			var backup export.Backup
+
+			if err := json.Unmarshal([]byte(buf.String()), &backup); err != nil {
+				t.Fatalf("decode %q: %v", buf.String(), err)
+			}
+
+			if backup.Version != export.BackupVersion {
+				t.Fatalf("version %d, want %d", backup.Version, export.BackupVersion)
+			}
+
+			if !reflect.DeepEqual(backup.Playlists, tt.want) {
+				t.Fatalf("playlists %+v, want %+v", backup.Playlists, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackupWriterOmitsEmptyFields(t *testing.T) {
+	var buf strings.Builder
+
+	bw, err := export.NewBackupWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bw.WritePlaylist(database.Playlist{Name: "plain"}, []database.Song{{Name: "song", Duration: 60}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, field := range []string{`"owner"`, `"volume"`, `"tags"`, `"favorite"`} {
+		if strings.Contains(buf.String(), field) {
+			t.Fatalf("backup %q contains empty field %s", buf.String(), field)
+		}
+	}
+}
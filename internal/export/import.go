@@ -0,0 +1,147 @@
+package export
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+
+	"gocloudcamp_test/internal/database"
+)
+
+var (
+	ErrInvalidExtinf   = errors.New("invalid #EXTINF line")
+	ErrMissingLocation = errors.New("#EXTINF is not followed by a song")
+)
+
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+func ParseM3U(r io.Reader, fallback uint) ([]database.Song, error) {
+	scanner := bufio.NewScanner(r)
+
+	var (
+		sns     []database.Song
+		pending *database.Song
+		at      int
+		line    int
+	)
+
+	for scanner.Scan() {
+		line++
+
+		text := strings.TrimSpace(scanner.Text())
+
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+
+		switch {
+		case text == "":
+		case strings.HasPrefix(text, "#EXTINF:"):
+			if pending != nil {
+				return nil, &ParseError{Line: at, Err: ErrMissingLocation}
+			}
+
+			sn, err := parseExtinf(strings.TrimPrefix(text, "#EXTINF:"), fallback)
+			if err != nil {
+				return nil, &ParseError{Line: line, Err: err}
+			}
+
+			pending, at = &sn, line
+		case strings.HasPrefix(text, "#"):
+		default:
+			sn := database.Song{Duration: database.Duration(fallback)}
+
+			if pending != nil {
+				sn, pending = *pending, nil
+			}
+
+			if sn.Name == "" {
+				sn.Name = locationName(text)
+			}
+
+			sns = append(sns, sn)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, &ParseError{Line: line + 1, Err: err}
+	}
+
+	if pending != nil {
+		return nil, &ParseError{Line: at, Err: ErrMissingLocation}
+	}
+
+	return sns, nil
+}
+
+func parseExtinf(text string, fallback uint) (database.Song, error) {
+	comma := titleComma(text)
+	if comma < 0 {
+		return database.Song{}, ErrInvalidExtinf
+	}
+
+	info, title := text[:comma], text[comma+1:]
+
+	fields := strings.Fields(info)
+	if len(fields) == 0 {
+		return database.Song{}, ErrInvalidExtinf
+	}
+
+	seconds, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return database.Song{}, ErrInvalidExtinf
+	}
+
+	duration := fallback
+
+	if seconds > 0 {
+		duration = uint(math.Min(math.Round(seconds), math.MaxUint32))
+	}
+
+	return database.Song{
+		Name:     strings.TrimSpace(title),
+		Duration: database.Duration(duration),
+	}, nil
+}
+
+func titleComma(text string) int {
+	quoted := false
+
+	for i, c := range text {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == ',' && !quoted:
+			return i
+		}
+	}
+
+	return -1
+}
+
+func locationName(location string) string {
+	name := path.Base(strings.ReplaceAll(location, "\\", "/"))
+	name = strings.TrimSuffix(name, path.Ext(name))
+
+	if name == "" || name == "." || name == "/" {
+		return location
+	}
+
+	return name
+}
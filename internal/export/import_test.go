@@ -0,0 +1,66 @@
+package export_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gocloudcamp_test/internal/database"
+	"gocloudcamp_test/internal/export"
+)
+
+func TestParseM3U(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []database.Song
+		line int
+		err  error
+	}{
+		{"extinf", "#EXTM3U\n#EXTINF:225,Intro\nintro.mp3\n", []database.Song{{Name: "Intro", Duration: 225}}, 0, nil},
+		{"location only", "#EXTM3U\nmusic/intro.mp3\n", []database.Song{{Name: "intro", Duration: 180}}, 0, nil},
+		{"windows location", "C:\\music\\intro.mp3\n", []database.Song{{Name: "intro", Duration: 180}}, 0, nil},
+		{"unknown duration", "#EXTINF:-1,Intro\nintro.mp3\n", []database.Song{{Name: "Intro", Duration: 180}}, 0, nil},
+		{"zero duration", "#EXTINF:0,Intro\nintro.mp3\n", []database.Song{{Name: "Intro", Duration: 180}}, 0, nil},
+		{"fractional duration", "#EXTINF:2.6,Intro\nintro.mp3\n", []database.Song{{Name: "Intro", Duration: 3}}, 0, nil},
+		{"empty title", "#EXTINF:60,\nintro.mp3\n", []database.Song{{Name: "intro", Duration: 60}}, 0, nil},
+		{"quoted attributes", "#EXTINF:60 tvg-name=\"a,b\",Intro\nintro.mp3\n", []database.Song{{Name: "Intro", Duration: 60}}, 0, nil},
+		{"bom", "\ufeff#EXTM3U\n#EXTINF:60,Intro\nintro.mp3\n", []database.Song{{Name: "Intro", Duration: 60}}, 0, nil},
+		{"bom before location", "\ufeffintro.mp3\n", []database.Song{{Name: "intro", Duration: 180}}, 0, nil},
+		{"crlf", "#EXTM3U\r\n#EXTINF:60,Intro\r\nintro.mp3\r\n", []database.Song{{Name: "Intro", Duration: 60}}, 0, nil},
+		{"comments and blank lines", "#EXTM3U\n\n# comment\n#EXTINF:60,Intro\n\nintro.mp3\n", []database.Song{{Name: "Intro", Duration: 60}}, 0, nil},
+		{"empty", "", nil, 0, nil},
+		{"invalid duration", "#EXTM3U\n#EXTINF:abc,Intro\nintro.mp3\n", nil, 2, export.ErrInvalidExtinf},
+		{"missing comma", "#EXTM3U\nintro.mp3\n#EXTINF:60 Intro\nintro.mp3\n", nil, 3, export.ErrInvalidExtinf},
+		{"missing length", "#EXTINF:,Intro\nintro.mp3\n", nil, 1, export.ErrInvalidExtinf},
+		{"extinf followed by extinf", "#EXTM3U\n#EXTINF:60,Intro\n#EXTINF:60,Outro\noutro.mp3\n", nil, 2, export.ErrMissingLocation},
+		{"extinf at end", "#EXTM3U\nintro.mp3\n#EXTINF:60,Outro\n", nil, 3, export.ErrMissingLocation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sns, err := export.ParseM3U(strings.NewReader(tt.in), 180)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err %v, want %v", err, tt.err)
+			}
+
+			if tt.err != nil {
+				var parseErr *export.ParseError
+
+				if !errors.As(err, &parseErr) || parseErr.Line != tt.line {
+					t.Fatalf("err %v, want a parse error on line %d", err, tt.line)
+				}
+
+				if prefix := fmt.Sprintf("line %d: ", tt.line); !strings.HasPrefix(err.Error(), prefix) {
+					t.Fatalf("message %q, want prefix %q", err.Error(), prefix)
+				}
+			}
+
+			if !reflect.DeepEqual(sns, tt.want) {
+				t.Fatalf("songs %+v, want %+v", sns, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"gocloudcamp_test/internal/database"
+)
+
+const (
+	M3UContentType = "audio/x-mpegurl"
+	M3UExtension   = ".m3u"
+)
+
+var lineBreaks = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ")
+
+func M3U(w io.Writer, pl database.Playlist, sns []database.Song) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "#EXTM3U")
+	fmt.Fprintf(bw, "#PLAYLIST:%s\n", lineBreaks.Replace(pl.Name))
+
+	for _, sn := range sns {
+		name := lineBreaks.Replace(sn.Name)
+
+		fmt.Fprintf(bw, "#EXTINF:%d,%s\n", sn.Duration, name)
+		fmt.Fprintln(bw, name)
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"gocloudcamp_test/internal/auth"
+	"gocloudcamp_test/internal/playlist"
+	"gocloudcamp_test/internal/service"
+
+	"github.com/go-chi/render"
+)
+
+const accessTokenParam = "access_token"
+
+var (
+	ErrForbidden = errors.New("playlist belongs to another user")
+	ErrAdminOnly = errors.New("admin access required")
+)
+
+func requestAuth(enabled bool, secret []byte) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !enabled {
+			return next
+		}
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			var claims *auth.Claims
+
+			token, err := bearerToken(r)
+			if err == nil {
+				claims, err = auth.Parse(secret, token)
+			}
+
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="player"`)
+
+				render.Render(w, r, responseUnauthorized(err))
+
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithClaims(r.Context(), claims)))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+func bearerToken(r *http.Request) (string, error) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		if allowsQueryToken(r.URL.Path) {
+			return r.URL.Query().Get(accessTokenParam), nil
+		}
+
+		return "", nil
+	}
+
+	scheme, token, _ := strings.Cut(header, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", auth.ErrNotBearer
+	}
+
+	return strings.TrimSpace(token), nil
+}
+
+func allowsQueryToken(path string) bool {
+	path = strings.TrimSuffix(path, "/")
+
+	return strings.HasSuffix(path, "/ws") || strings.HasSuffix(path, "/events")
+}
+
+func redactedURI(r *http.Request) string {
+	path, query, ok := strings.Cut(r.RequestURI, "?")
+	if !ok {
+		return r.RequestURI
+	}
+
+	params := strings.Split(query, "&")
+
+	for i, param := range params {
+		if key, _, _ := strings.Cut(param, "="); key == accessTokenParam {
+			params[i] = accessTokenParam + "=REDACTED"
+		}
+	}
+
+	return path + "?" + strings.Join(params, "&")
+}
+
+func ownerFilter(r *http.Request) (string, bool) {
+	claims, ok := auth.FromContext(r.Context())
+	if !ok || claims.Admin {
+		return "", false
+	}
+
+	return claims.UserId, true
+}
+
+func canAccess(r *http.Request, pl *playlist.Playlist) bool {
+	userId, restricted := ownerFilter(r)
+
+	return !restricted || pl.Owner == userId
+}
+
+func requireOwner(s *service.Service) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if _, restricted := ownerFilter(r); !restricted {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			id, err := parseId(r, "id")
+			if err != nil {
+				render.Render(w, r, responseInvalidRequest(err))
+
+				return
+			}
+
+			pl, err := s.GetPlaylist(id)
+			if err != nil {
+				render.Render(w, r, responseError(err))
+
+				return
+			}
+
+			if !canAccess(r, pl) {
+				render.Render(w, r, responseForbidden(ErrForbidden))
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+func requireAdmin() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if _, restricted := ownerFilter(r); restricted {
+				render.Render(w, r, responseForbidden(ErrAdminOnly))
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gocloudcamp_test/internal/auth"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var testSecret = []byte("secret")
+
+func signTestToken(t *testing.T, userId string) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{UserId: userId}).SignedString(testSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token
+}
+
+func TestRequestAuth(t *testing.T) {
+	token := signTestToken(t, "alice")
+
+	tests := []struct {
+		name          string
+		target        string
+		authorization string
+		status        int
+		code          string
+	}{
+		{"bearer header", "/v1/playlist", "Bearer " + token, http.StatusOK, ""},
+		{"lowercase scheme", "/v1/playlist", "bearer " + token, http.StatusOK, ""},
+		{"missing token", "/v1/playlist", "", http.StatusUnauthorized, "missing_token"},
+		{"bare token", "/v1/playlist", token, http.StatusUnauthorized, "invalid_scheme"},
+		{"basic scheme", "/v1/playlist", "Basic dXNlcjpwYXNz", http.StatusUnauthorized, "invalid_scheme"},
+		{"invalid token", "/v1/playlist", "Bearer invalid", http.StatusUnauthorized, "invalid_token"},
+		{"query token on regular route", "/v1/playlist?access_token=" + token, "", http.StatusUnauthorized, "missing_token"},
+		{"query token on websocket", "/v1/playlist/1/ws?access_token=" + token, "", http.StatusOK, ""},
+		{"query token on events", "/v1/playlist/1/events?access_token=" + token, "", http.StatusOK, ""},
+		{"header wins over query", "/v1/playlist/1/ws?access_token=" + token, "Basic dXNlcjpwYXNz", http.StatusUnauthorized, "invalid_scheme"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var userId string
+
+			handler := requestAuth(true, testSecret)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if claims, ok := auth.FromContext(r.Context()); ok {
+					userId = claims.UserId
+				}
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+
+			if tt.code != "" {
+				if code := decodeError(t, rec).Code; code != tt.code {
+					t.Fatalf("code %q, want %q", code, tt.code)
+				}
+
+				return
+			}
+
+			if userId != "alice" {
+				t.Fatalf("user id %q in context, want %q", userId, "alice")
+			}
+		})
+	}
+}
+
+func TestRedactedURI(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{"/v1/playlist", "/v1/playlist"},
+		{"/v1/playlist?limit=10", "/v1/playlist?limit=10"},
+		{"/v1/playlist/1/ws?access_token=secret", "/v1/playlist/1/ws?access_token=REDACTED"},
+		{"/v1/playlist/1/events?a=1&access_token=secret&b=2", "/v1/playlist/1/events?a=1&access_token=REDACTED&b=2"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.uri, nil)
+
+		if got := redactedURI(req); got != tt.want {
+			t.Errorf("redactedURI(%q) = %q, want %q", tt.uri, got, tt.want)
+		}
+	}
+}
+
+func TestRequestLoggerRedactsToken(t *testing.T) {
+	var buf bytes.Buffer
+
+	handler := requestLogger(slog.New(slog.NewTextHandler(&buf, nil)))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/playlist/1/ws?access_token=secret-token", nil))
+
+	if strings.Contains(buf.String(), "secret-token") {
+		t.Fatalf("access token leaked into the request log: %s", buf.String())
+	}
+
+	if !strings.Contains(buf.String(), "access_token=REDACTED") {
+		t.Fatalf("request log lacks the redacted uri: %s", buf.String())
+	}
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+
+	"gocloudcamp_test/internal/auth"
+	"gocloudcamp_test/internal/export"
+	"gocloudcamp_test/internal/playlist"
+	"gocloudcamp_test/internal/service"
+)
+
+type errorCodeEntry struct {
+	err  error
+	code string
+}
+
+var errorCodes = []errorCodeEntry{
+	{ErrParseId, "invalid_id"},
+	{ErrRequestBody, "invalid_body"},
+	{ErrRequestTooLarge, "body_too_large"},
+	{ErrInternal, "internal_error"},
+	{ErrNoSongsProvided, "no_songs_provided"},
+	{ErrNoPlaylistsProvided, "no_playlists_provided"},
+	{ErrInvalidLimit, "invalid_limit"},
+	{ErrInvalidOffset, "invalid_offset"},
+	{ErrInvalidPosition, "invalid_position"},
+	{ErrInvalidDedup, "invalid_dedup"},
+	{ErrInvalidFormat, "invalid_format"},
+	{ErrInvalidFields, "invalid_fields"},
+	{ErrInvalidExpand, "invalid_expand"},
+	{ErrForbidden, "forbidden"},
+	{ErrAdminOnly, "admin_only"},
+	{ErrRateLimited, "rate_limited"},
+	{ErrWriteBusy, "write_busy"},
+	{ErrInvalidIfMatch, "invalid_if_match"},
+	{ErrIfMatchRequired, "if_match_required"},
+	{service.ErrVersionMismatch, "version_mismatch"},
+	{ErrRequestTimeout, "request_timeout"},
+	{ErrStreamingUnsupported, "streaming_unsupported"},
+
+	{auth.ErrMissingToken, "missing_token"},
+	{auth.ErrInvalidToken, "invalid_token"},
+	{auth.ErrNotBearer, "invalid_scheme"},
+
+	{export.ErrInvalidExtinf, "invalid_m3u"},
+	{export.ErrMissingLocation, "invalid_m3u"},
+	{export.ErrBackupVersion, "unsupported_version"},
+
+	{service.ErrPlaylistNotFound, "playlist_not_found"},
+	{service.ErrAlreadyExists, "playlist_exists"},
+	{service.ErrPlaylistLaunched, "playlist_launched"},
+	{service.ErrSameTarget, "same_target"},
+	{service.ErrAlreadyLaunched, "already_launched"},
+	{service.ErrLaunchLimit, "launch_limit"},
+	{service.ErrShuttingDown, "shutting_down"},
+	{service.ErrInvalidDuration, "invalid_duration"},
+	{service.ErrInvalidName, "invalid_name"},
+	{service.ErrDuplicateName, "duplicate_name"},
+	{service.ErrUnavailable, "database_unavailable"},
+	{service.ErrInvalidStatus, "invalid_status"},
+	{service.ErrInvalidSort, "invalid_sort"},
+	{service.ErrInvalidCursor, "invalid_cursor"},
+	{service.ErrEmptyQuery, "empty_query"},
+	{service.ErrNotShared, "not_shared"},
+	{service.ErrShareNotFound, "share_not_found"},
+	{service.ErrInvalidSleep, "invalid_sleep"},
+	{service.ErrScheduleInPast, "schedule_in_past"},
+	{service.ErrScheduleNotFound, "schedule_not_found"},
+	{service.ErrInvalidWebhook, "invalid_webhook"},
+	{service.ErrInvalidVolume, "invalid_volume"},
+	{service.ErrIdempotencyMismatch, "idempotency_mismatch"},
+
+	{playlist.ErrNoSongs, "no_songs"},
+	{playlist.ErrNotProcessed, "not_launched"},
+	{playlist.ErrAlreadyProcessing, "already_processing"},
+	{playlist.ErrAlreadyStopped, "already_stopped"},
+	{playlist.ErrAlreadyPlaying, "already_playing"},
+	{playlist.ErrAlreadyPaused, "already_paused"},
+	{playlist.ErrEndOfPlaylist, "end_of_playlist"},
+	{playlist.ErrStartOfPlaylist, "start_of_playlist"},
+	{playlist.ErrSongNotIn, "song_not_found"},
+	{playlist.ErrSongIdTaken, "song_id_taken"},
+	{playlist.ErrRemoveFromEmpty, "playlist_empty"},
+	{playlist.ErrRemovePlaying, "song_playing"},
+	{playlist.ErrRemoveNotIn, "song_not_found"},
+	{playlist.ErrEditCurrent, "song_current"},
+	{playlist.ErrLargerTime, "time_out_of_range"},
+	{playlist.ErrInvalidRepeat, "invalid_repeat"},
+	{playlist.ErrInvalidSpeed, "invalid_speed"},
+	{playlist.ErrIndexOutOfRange, "index_out_of_range"},
+	{playlist.ErrQueueFull, "queue_full"},
+	{playlist.ErrSwapSame, "swap_same"},
+}
+
+func errorCode(err error, fallback string) string {
+	for _, entry := range errorCodes {
+		if errors.Is(err, entry.err) {
+			return entry.code
+		}
+	}
+
+	return fallback
+}
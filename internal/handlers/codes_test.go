@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"gocloudcamp_test/internal/playlist"
+	"gocloudcamp_test/internal/service"
+)
+
+func TestErrorCodes(t *testing.T) {
+	for _, entry := range errorCodes {
+		t.Run(entry.code, func(t *testing.T) {
+			if code := errorCode(entry.err, "fallback"); code != entry.code {
+				t.Fatalf("code %q for %v, want %q", code, entry.err, entry.code)
+			}
+
+			if code := errorCode(fmt.Errorf("wrapped: %w", entry.err), "fallback"); code != entry.code {
+				t.Fatalf("code %q for wrapped %v, want %q", code, entry.err, entry.code)
+			}
+		})
+	}
+}
+
+func TestErrorCodeUnknown(t *testing.T) {
+	if code := errorCode(errors.New("unknown"), "fallback"); code != "fallback" {
+		t.Fatalf("code %q, want %q", code, "fallback")
+	}
+}
+
+func TestResponseErrorCodes(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{service.ErrPlaylistNotFound, http.StatusNotFound, "playlist_not_found"},
+		{playlist.ErrSongNotIn, http.StatusNotFound, "song_not_found"},
+		{service.ErrUnavailable, http.StatusServiceUnavailable, "database_unavailable"},
+		{service.ErrShuttingDown, http.StatusServiceUnavailable, "shutting_down"},
+		{playlist.ErrNotProcessed, http.StatusConflict, "not_launched"},
+		{playlist.ErrAlreadyPaused, http.StatusConflict, "already_paused"},
+		{playlist.ErrLargerTime, http.StatusUnprocessableEntity, "time_out_of_range"},
+		{errors.New("unknown"), http.StatusInternalServerError, "internal_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			resp, ok := responseError(tt.err).(*errorResponse)
+			if !ok {
+				t.Fatalf("response %T, want *errorResponse", responseError(tt.err))
+			}
+
+			if resp.HTTPStatusCode != tt.status {
+				t.Fatalf("status %d, want %d", resp.HTTPStatusCode, tt.status)
+			}
+
+			if resp.Code != tt.code {
+				t.Fatalf("code %q, want %q", resp.Code, tt.code)
+			}
+		})
+	}
+}
+
+func TestHandlerErrorCodes(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		status int
+		code   string
+	}{
+		{"unknown playlist", http.MethodGet, "/v1/playlist/999", "", http.StatusNotFound, "playlist_not_found"},
+		{"invalid id", http.MethodGet, "/v1/playlist/abc", "", http.StatusBadRequest, "invalid_id"},
+		{"invalid body", http.MethodPost, "/v1/playlist", "{", http.StatusBadRequest, "invalid_body"},
+		{"invalid name", http.MethodPost, "/v1/playlist", `{"Name":" "}`, http.StatusUnprocessableEntity, "invalid_name"},
+		{"invalid duration", http.MethodPost, "/v1/playlist", `{"Name":"a","Songs":[{"Duration":0}]}`, http.StatusUnprocessableEntity, "invalid_duration"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(t, service.Config{})
+
+			rec := ts.do(t, tt.method, tt.target, tt.body)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+
+			if code := decodeError(t, rec).Code; code != tt.code {
+				t.Fatalf("code %q, want %q", code, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+)
+
+const gzipThreshold = 1024
+
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	buf         []byte
+	gz          *gzip.Writer
+	passthrough bool
+	wroteHeader bool
+}
+
+func (gw *gzipResponseWriter) WriteHeader(code int) {
+	if gw.status == 0 {
+		gw.status = code
+	}
+}
+
+func (gw *gzipResponseWriter) writeHeader() {
+	if gw.wroteHeader {
+		return
+	}
+
+	gw.wroteHeader = true
+
+	if gw.status == 0 {
+		gw.status = http.StatusOK
+	}
+
+	gw.ResponseWriter.WriteHeader(gw.status)
+}
+
+func (gw *gzipResponseWriter) start() error {
+	if gw.Header().Get("Content-Encoding") != "" {
+		gw.passthrough = true
+		gw.writeHeader()
+
+		_, err := gw.ResponseWriter.Write(gw.buf)
+		gw.buf = nil
+
+		return err
+	}
+
+	gw.Header().Set("Content-Encoding", "gzip")
+	gw.Header().Del("Content-Length")
+	gw.writeHeader()
+
+	gw.gz = gzip.NewWriter(gw.ResponseWriter)
+
+	_, err := gw.gz.Write(gw.buf)
+	gw.buf = nil
+
+	return err
+}
+
+func (gw *gzipResponseWriter) Write(p []byte) (int, error) {
+	switch {
+	case gw.passthrough:
+		gw.writeHeader()
+
+		return gw.ResponseWriter.Write(p)
+	case gw.gz != nil:
+		return gw.gz.Write(p)
+	}
+
+	gw.buf = append(gw.buf, p...)
+
+	if len(gw.buf) >= gzipThreshold {
+		if err := gw.start(); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+func (gw *gzipResponseWriter) Flush() {
+	if gw.gz != nil {
+		gw.gz.Flush()
+	} else if !gw.passthrough {
+		gw.passthrough = true
+		gw.writeHeader()
+		gw.ResponseWriter.Write(gw.buf)
+		gw.buf = nil
+	}
+
+	if f, ok := gw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (gw *gzipResponseWriter) close() {
+	if gw.gz != nil {
+		gw.gz.Close()
+
+		return
+	}
+
+	if gw.wroteHeader {
+		return
+	}
+
+	gw.writeHeader()
+	gw.ResponseWriter.Write(gw.buf)
+}
+
+func requestCompress() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") || r.Header.Get("Upgrade") != "" {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			w.Header().Add("Vary", "Accept-Encoding")
+
+			gw := &gzipResponseWriter{ResponseWriter: w}
+
+			next.ServeHTTP(gw, r)
+
+			gw.close()
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
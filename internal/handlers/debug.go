@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/pprof"
+
+	"gocloudcamp_test/internal/service"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+)
+
+func profiler() http.Handler {
+	router := chi.NewRouter()
+
+	router.HandleFunc("/", pprof.Index)
+	router.HandleFunc("/cmdline", pprof.Cmdline)
+	router.HandleFunc("/profile", pprof.Profile)
+	router.HandleFunc("/symbol", pprof.Symbol)
+	router.HandleFunc("/trace", pprof.Trace)
+	router.HandleFunc("/{profile}", pprof.Index)
+
+	return router
+}
+
+func workers(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		render.Render(w, r, &workersResponse{
+			HTTPStatusCode: http.StatusOK,
+			Workers:        s.Workers(),
+		})
+	}
+}
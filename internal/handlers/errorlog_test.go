@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"gocloudcamp_test/internal/metrics"
+	"gocloudcamp_test/internal/service"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestStalledErrorLog(t *testing.T) {
+	ts := newTestServer(t, service.Config{ErrorLogBuffer: 1})
+	pl := ts.playlist(t, "stalled")
+
+	ts.store.Fail(func(string) error { return errors.New("database exploded") })
+
+	dropped := testutil.ToFloat64(metrics.DroppedErrors)
+
+	const n = 10
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for i := 0; i < n; i++ {
+			rec := ts.do(t, http.MethodDelete, fmt.Sprintf("/v1/playlist/%d", pl.Id), "")
+
+			if rec.Code != http.StatusInternalServerError {
+				t.Errorf("status %d, want %d: %s", rec.Code, http.StatusInternalServerError, rec.Body.String())
+			}
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Fatal("handlers blocked on a stalled error log consumer")
+	}
+
+	if got := testutil.ToFloat64(metrics.DroppedErrors) - dropped; got != n-1 {
+		t.Fatalf("%v dropped errors, want %d", got, n-1)
+	}
+}
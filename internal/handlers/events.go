@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gocloudcamp_test/internal/service"
+
+	"github.com/go-chi/render"
+)
+
+var ErrStreamingUnsupported = errors.New("streaming is not supported")
+
+type progressMessage struct {
+	Index    int  `json:"index"`
+	SongId   uint `json:"song_id"`
+	Elapsed  uint `json:"elapsed"`
+	Duration uint `json:"duration"`
+}
+
+func writeEvent(w http.ResponseWriter, name string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data)
+
+	return err
+}
+
+func eventsPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		pl, err := s.GetPlaylist(id)
+		if err != nil {
+			render.Render(w, r, responseError(err))
+
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			render.Render(w, r, responseInternalError(ErrStreamingUnsupported))
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		events, unsubscribe := pl.Subscribe()
+		defer unsubscribe()
+
+		ticker := time.NewTicker(s.Config().ProgressInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case ev, ok := <-events:
+				if !ok {
+					return
+				}
+
+				if err := writeEvent(w, string(ev), newPlaylistData(pl)); err != nil {
+					return
+				}
+			case <-ticker.C:
+				curr := pl.Current()
+				if curr == nil {
+					continue
+				}
+
+				elapsed, duration := pl.Elapsed()
+
+				if err := writeEvent(w, "progress", &progressMessage{
+					Index:    curr.Index,
+					SongId:   curr.Id,
+					Elapsed:  elapsed,
+					Duration: duration,
+				}); err != nil {
+					return
+				}
+			}
+
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gocloudcamp_test/internal/service"
+)
+
+func TestEventsDefaultProgressInterval(t *testing.T) {
+	ts := newTestServer(t, service.Config{})
+	pl := ts.playlist(t, "playlist", 60)
+	ts.launch(t, pl)
+
+	if err := pl.Play(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ts.s.Config().ProgressInterval+time.Millisecond*500)
+	defer cancel()
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/v1/playlist/%d/events", pl.Id), nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+
+	ts.handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	if !strings.Contains(rec.Body.String(), "event: progress") {
+		t.Fatalf("no progress event in %q", rec.Body.String())
+	}
+}
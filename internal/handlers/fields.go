@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrInvalidFields = errors.New("fields must be a comma separated list of status, current_song, total_duration, volume, song_count, songs")
+	ErrInvalidExpand = errors.New("expand must be songs")
+)
+
+var playlistFields = []string{"status", "current_song", "total_duration", "volume", "song_count", "songs"}
+
+type fieldSet map[string]bool
+
+func (fs fieldSet) has(field string) bool {
+	return fs == nil || fs[field]
+}
+
+func parseFields(r *http.Request) (fieldSet, error) {
+	value := r.URL.Query().Get("fields")
+	if value == "" {
+		return nil, nil
+	}
+
+	fields := fieldSet{}
+
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+
+		if !isPlaylistField(field) {
+			return nil, ErrInvalidFields
+		}
+
+		fields[field] = true
+	}
+
+	return fields, nil
+}
+
+func isPlaylistField(field string) bool {
+	for _, known := range playlistFields {
+		if field == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseExpand(r *http.Request, fallback bool) (bool, error) {
+	switch r.URL.Query().Get("expand") {
+	case "":
+		return fallback, nil
+	case "songs":
+		return true, nil
+	}
+
+	return false, ErrInvalidExpand
+}
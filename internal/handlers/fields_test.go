@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"gocloudcamp_test/internal/service"
+)
+
+type testPlaylist struct {
+	Songs    []json.RawMessage `json:"songs"`
+	SongsUrl string            `json:"songs_url"`
+}
+
+func TestExpandSongs(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		header   []string
+		listing  bool
+		songs    bool
+		songsUrl string
+	}{
+		{"listing default", "/v1/playlist", nil, true, false, "http://example.com/v1/playlist/%d/songs"},
+		{"listing expanded", "/v1/playlist?expand=songs", nil, true, true, ""},
+		{"listing behind proxy", "/v1/playlist", []string{"X-Forwarded-Proto", "https", "X-Forwarded-Host", "player.example.org", "X-Forwarded-Prefix", "/api"}, true, false, "https://player.example.org/api/v1/playlist/%d/songs"},
+		{"playlist default", "/v1/playlist/%d", nil, false, true, ""},
+		{"playlist expanded", "/v1/playlist/%d?expand=songs", nil, false, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(t, service.Config{TrustedProxies: []string{"192.0.2.1"}})
+			pl := ts.playlist(t, "playlist", 10, 20)
+
+			target := tt.target
+			if !tt.listing {
+				target = fmt.Sprintf(tt.target, pl.Id)
+			}
+
+			rec := ts.do(t, http.MethodGet, target, "", tt.header...)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+			}
+
+			var resp struct {
+				Playlist  testPlaylist   `json:"playlist"`
+				Playlists []testPlaylist `json:"playlists"`
+			}
+
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+
+			got := resp.Playlist
+			if tt.listing {
+				if len(resp.Playlists) != 1 {
+					t.Fatalf("%d playlists in listing, want 1", len(resp.Playlists))
+				}
+
+				got = resp.Playlists[0]
+			}
+
+			if songs := len(got.Songs) == 2; songs != tt.songs {
+				t.Fatalf("%d songs inlined, want songs %t", len(got.Songs), tt.songs)
+			}
+
+			want := ""
+			if tt.songsUrl != "" {
+				want = fmt.Sprintf(tt.songsUrl, pl.Id)
+			}
+
+			if got.SongsUrl != want {
+				t.Fatalf("songs_url %q, want %q", got.SongsUrl, want)
+			}
+		})
+	}
+}
+
+func TestInvalidExpand(t *testing.T) {
+	ts := newTestServer(t, service.Config{})
+	pl := ts.playlist(t, "playlist", 10)
+
+	for _, target := range []string{"/v1/playlist?expand=all", fmt.Sprintf("/v1/playlist/%d?expand=all", pl.Id)} {
+		rec := ts.do(t, http.MethodGet, target, "")
+
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status %d, want %d", target, rec.Code, http.StatusBadRequest)
+		}
+
+		if code := decodeError(t, rec).Code; code != "invalid_expand" {
+			t.Fatalf("%s: code %q, want %q", target, code, "invalid_expand")
+		}
+	}
+}
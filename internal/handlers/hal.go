@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type baseUrlCtxKey struct{}
+
+type halLink struct {
+	Href string `json:"href"`
+}
+
+type halLinks map[string]halLink
+
+func forwardedValue(r *http.Request, header string) string {
+	value, _, _ := strings.Cut(r.Header.Get(header), ",")
+
+	return strings.TrimSpace(value)
+}
+
+func requestBaseUrl(proxies []string) func(next http.Handler) http.Handler {
+	trusted := parseTrustedProxies(proxies)
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			base := url.URL{Scheme: "http", Host: r.Host}
+
+			if r.TLS != nil {
+				base.Scheme = "https"
+			}
+
+			ip, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				ip = r.RemoteAddr
+			}
+
+			if isTrusted(trusted, ip) {
+				if proto := forwardedValue(r, "X-Forwarded-Proto"); proto == "http" || proto == "https" {
+					base.Scheme = proto
+				}
+
+				if host := forwardedValue(r, "X-Forwarded-Host"); host != "" {
+					base.Host = host
+				}
+
+				base.Path = strings.TrimSuffix(forwardedValue(r, "X-Forwarded-Prefix"), "/")
+			}
+
+			ctx := context.WithValue(r.Context(), baseUrlCtxKey{}, base.String())
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+func baseUrl(r *http.Request) string {
+	base, _ := r.Context().Value(baseUrlCtxKey{}).(string)
+
+	return base
+}
+
+func addHALLinks(r *http.Request, v any) {
+	base := baseUrl(r)
+
+	switch v := v.(type) {
+	case *playlistResponse:
+		v.Playlist.Links = playlistLinks(base, v.Playlist.id)
+	case *allResponse:
+		for i := range v.Playlists {
+			v.Playlists[i].Links = playlistLinks(base, v.Playlists[i].id)
+		}
+
+		v.Links = listingLinks(base, r.URL, v.offset, v.limit, v.Total)
+	}
+}
+
+func playlistLinks(base string, id uint) halLinks {
+	self := base + playlistLocation(id)
+
+	return halLinks{
+		"self":   {Href: self},
+		"songs":  {Href: self + "/songs"},
+		"launch": {Href: self + "/launch"},
+		"stop":   {Href: self + "/stop"},
+	}
+}
+
+func listingLinks(base string, u *url.URL, offset, limit, total int) halLinks {
+	page := func(offset int) halLink {
+		query := u.Query()
+		query.Set("offset", strconv.Itoa(offset))
+		query.Set("limit", strconv.Itoa(limit))
+
+		return halLink{Href: base + u.Path + "?" + query.Encode()}
+	}
+
+	links := halLinks{"self": page(offset)}
+
+	if offset+limit < total {
+		links["next"] = page(offset + limit)
+	}
+
+	if offset > 0 {
+		links["prev"] = page(max(offset-limit, 0))
+	}
+
+	return links
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"gocloudcamp_test/internal/service"
+)
+
+func TestPlaylistHALLinks(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []string
+		base   string
+	}{
+		{"direct", nil, "http://example.com"},
+		{"behind proxy", []string{"X-Forwarded-Proto", "https", "X-Forwarded-Host", "player.example.org", "X-Forwarded-Prefix", "/api/"}, "https://player.example.org/api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(t, service.Config{TrustedProxies: []string{"192.0.2.1"}})
+			pl := ts.playlist(t, "hal", 60)
+
+			rec := ts.do(t, http.MethodGet, fmt.Sprintf("/v1/playlist/%d", pl.Id), "", append([]string{"Accept", mediaTypeHAL}, tt.header...)...)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+			}
+
+			var resp struct {
+				Playlist struct {
+					Links halLinks `json:"_links"`
+				} `json:"playlist"`
+			}
+
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+
+			self := fmt.Sprintf("%s/v1/playlist/%d", tt.base, pl.Id)
+			want := halLinks{
+				"self":   {Href: self},
+				"songs":  {Href: self + "/songs"},
+				"launch": {Href: self + "/launch"},
+				"stop":   {Href: self + "/stop"},
+			}
+
+			if !reflect.DeepEqual(resp.Playlist.Links, want) {
+				t.Fatalf("links %v, want %v", resp.Playlist.Links, want)
+			}
+		})
+	}
+}
+
+func TestPlaylistWithoutHAL(t *testing.T) {
+	ts := newTestServer(t, service.Config{})
+	pl := ts.playlist(t, "hal", 60)
+
+	rec := ts.do(t, http.MethodGet, fmt.Sprintf("/v1/playlist/%d", pl.Id), "", "Accept", "application/json")
+
+	var resp struct {
+		Playlist map[string]json.RawMessage `json:"playlist"`
+	}
+
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := resp.Playlist["_links"]; ok {
+		t.Fatalf("plain json response has links: %s", rec.Body.String())
+	}
+}
+
+func TestListingHALLinks(t *testing.T) {
+	page := func(offset int) halLink {
+		return halLink{Href: fmt.Sprintf("http://example.com/v1/playlist?limit=1&offset=%d", offset)}
+	}
+
+	tests := []struct {
+		offset int
+		want   halLinks
+	}{
+		{0, halLinks{"self": page(0), "next": page(1)}},
+		{1, halLinks{"self": page(1), "next": page(2), "prev": page(0)}},
+		{2, halLinks{"self": page(2), "prev": page(1)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.offset), func(t *testing.T) {
+			ts := newTestServer(t, service.Config{})
+
+			ids := make([]uint, 3)
+
+			for i := range ids {
+				ids[i] = ts.playlist(t, fmt.Sprintf("hal %d", i), 60).Id
+			}
+
+			rec := ts.do(t, http.MethodGet, fmt.Sprintf("/v1/playlist?limit=1&offset=%d", tt.offset), "", "Accept", mediaTypeHAL)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+			}
+
+			var resp struct {
+				Playlists []struct {
+					Status struct{ Id uint } `json:"status"`
+					Links  halLinks          `json:"_links"`
+				} `json:"playlists"`
+				Links halLinks `json:"_links"`
+			}
+
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(resp.Links, tt.want) {
+				t.Fatalf("links %v, want %v", resp.Links, tt.want)
+			}
+
+			if len(resp.Playlists) != 1 || resp.Playlists[0].Status.Id != ids[tt.offset] {
+				t.Fatalf("playlists %+v, want playlist %d", resp.Playlists, ids[tt.offset])
+			}
+
+			if self, want := resp.Playlists[0].Links["self"].Href, fmt.Sprintf("http://example.com/v1/playlist/%d", ids[tt.offset]); self != want {
+				t.Fatalf("playlist self link %q, want %q", self, want)
+			}
+		})
+	}
+}
@@ -1,59 +1,168 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
+	"gocloudcamp_test/internal/auth"
+	"gocloudcamp_test/internal/build"
 	"gocloudcamp_test/internal/database"
+	"gocloudcamp_test/internal/export"
+	"gocloudcamp_test/internal/metrics"
+	"gocloudcamp_test/internal/playlist"
 	"gocloudcamp_test/internal/service"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/render"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
-	ErrParseId         = errors.New("can't parse id")
-	ErrRequestBody     = errors.New("there is an error in the request body")
-	ErrNoSongsProvided = errors.New("no songs provided")
+	ErrParseId             = errors.New("can't parse id")
+	ErrRequestBody         = errors.New("there is an error in the request body")
+	ErrRequestTooLarge     = errors.New("request body is too large")
+	ErrInternal            = errors.New("internal server error")
+	ErrNoSongsProvided     = errors.New("no songs provided")
+	ErrNoPlaylistsProvided = errors.New("no playlists provided")
+	ErrInvalidLimit        = errors.New("limit must be a positive number")
+	ErrInvalidOffset       = errors.New("offset must be a non-negative number")
+	ErrInvalidPosition     = errors.New("position must be a number")
+	ErrInvalidFormat       = errors.New("format must be json or m3u")
+	ErrInvalidDedup        = errors.New("dedup must be true or false")
+)
+
+const (
+	defaultLimit  = 50
+	defaultTop    = 10
+	defaultRecent = 20
+	healthTimeout = time.Second * 2
 )
 
 func New(ctx context.Context, s *service.Service) http.Handler {
 	router := chi.NewRouter()
 
-	router.Use(requestLogger())
+	router.Use(requestRecoverer(s))
+	router.Use(requestNegotiate())
+	router.Use(requestBaseUrl(s.Config().TrustedProxies))
+	router.Use(middleware.RequestID)
+	router.Use(requestLogger(s.Logger()))
+	router.Use(requestMetrics())
+	router.Use(requestTracer(s.TracerProvider()))
+	router.Use(requestCors(s.Config().CorsOrigins))
+	router.Use(requestRateLimit(ctx, s.Config().RateLimit, s.Config().RateBurst, s.Config().TrustedProxies))
+	router.Use(requestWriteLimit(s.Config().WriteConcurrency, s.Config().WriteQueueWait))
+	router.Use(requestBodyLimit(s.Config().MaxBodySize))
+	router.Use(requestCompress())
+	router.Use(requestTimeout(s.Config().RequestTimeout))
 	router.Use(middleware.StripSlashes)
-	router.Use(render.SetContentType(render.ContentTypeJSON))
 
 	router.NotFound(notFound)
 	router.MethodNotAllowed(notAllowed)
 
 	router.Get("/ping", ping)
+	router.Get("/health", health(s))
+	router.Get("/version", version)
+	router.Handle("/metrics", metrics.Handler())
+	router.Get("/openapi.json", openapi(router))
+	router.Get("/docs", docs)
+
+	if s.Config().PprofEnabled {
+		router.Group(func(debug chi.Router) {
+			debug.Use(requestAuth(s.Config().AuthEnabled, []byte(s.Config().AuthSecret)))
+			debug.Use(requireAdmin())
+
+			debug.Mount("/debug/pprof", profiler())
+		})
+	}
 
 	router.Route("/v1", func(v1 chi.Router) {
-		v1.Route("/playlist", func(pl chi.Router) {
-			pl.Get("/", getAll(s))
-			pl.Get("/{id}", getPlaylist(s))
-
-			pl.Post("/", newPlaylist(s))
-			pl.Patch("/{id}/name", namePlaylist(s))
-			pl.Patch("/{id}/time", timePlaylist(s))
-			pl.Delete("/{id}", deletePlaylist(s))
-
-			pl.Post("/{id}/launch", launchPlaylist(ctx, s))
-			pl.Post("/{id}/stop", stopPlaylist(s))
-
-			pl.Post("/{id}/play", playPlaylist(s))
-			pl.Post("/{id}/pause", pausePlaylist(s))
-			pl.Post("/{id}/next", nextPlaylist(s))
-			pl.Post("/{id}/prev", prevPlaylist(s))
-
-			pl.Post("/{id}/song", addSong(s))
-			pl.Patch("/{id}/song/{sid}", editSong(s))
-			pl.Delete("/{id}/song/{sid}", removeSong(s))
+		v1.Get("/shared/{token}", sharedPlaylist(s))
+
+		v1.Group(func(private chi.Router) {
+			private.Use(requestAuth(s.Config().AuthEnabled, []byte(s.Config().AuthSecret)))
+
+			private.Post("/playlists/batch", batchPlaylists(s))
+			private.Post("/playlists/stop-all", stopAll(s))
+			private.Post("/playlists/pause-all", pauseAll(s))
+			private.Get("/stats", stats(s))
+			private.With(requireAdmin()).Get("/debug/workers", workers(s))
+			private.Get("/songs/search", searchSongs(s))
+			private.Get("/songs/favorites", favoriteSongs(s))
+			private.Get("/songs/{name}/playlists", songPlaylists(s))
+			private.Get("/export", exportAll(s))
+			private.Post("/import", importAll(s))
+
+			private.Route("/playlist", func(pl chi.Router) {
+				pl.Get("/", getAll(s))
+				pl.Post("/", newPlaylist(s))
+				pl.Post("/import", importPlaylist(s))
+				pl.Post("/{id}/restore", restorePlaylist(s))
+				pl.With(requireAdmin()).Delete("/{id}/purge", purgePlaylist(s))
+
+				pl.Group(func(one chi.Router) {
+					one.Use(requireOwner(s))
+					one.Use(requireVersion(s))
+
+					one.Get("/{id}", getPlaylist(s))
+					one.Get("/{id}/export", exportPlaylist(s))
+					one.Get("/{id}/ws", socketPlaylist(s))
+					one.Get("/{id}/events", eventsPlaylist(s))
+
+					one.Patch("/{id}/name", namePlaylist(s))
+					one.Get("/{id}/time", elapsedPlaylist(s))
+					one.Patch("/{id}/time", timePlaylist(s))
+					one.Get("/{id}/remaining", remainingPlaylist(s))
+					one.Get("/{id}/top", topSongs(s))
+					one.Get("/{id}/history", historyPlaylist(s))
+					one.Patch("/{id}/shuffle", shufflePlaylist(s))
+					one.Patch("/{id}/repeat", repeatPlaylist(s))
+					one.Patch("/{id}/speed", speedPlaylist(s))
+					one.Patch("/{id}/volume", volumePlaylist(s))
+					one.Delete("/{id}", deletePlaylist(s))
+					one.Post("/{id}/clone", clonePlaylist(s))
+					one.Post("/{id}/share", sharePlaylist(s))
+					one.Delete("/{id}/share", unsharePlaylist(s))
+
+					one.Post("/{id}/launch", launchPlaylist(ctx, s))
+					one.Post("/{id}/stop", stopPlaylist(s))
+
+					one.Post("/{id}/play", playPlaylist(s))
+					one.Post("/{id}/pause", pausePlaylist(s))
+					one.Post("/{id}/next", nextPlaylist(s))
+					one.Post("/{id}/prev", prevPlaylist(s))
+					one.Post("/{id}/seek", seekPlaylist(s))
+					one.Post("/{id}/restart", restartPlaylist(s))
+					one.Post("/{id}/queue", queueSong(s))
+					one.Post("/{id}/sleep", sleepPlaylist(s))
+					one.Post("/{id}/schedule", schedulePlaylist(ctx, s))
+					one.Delete("/{id}/schedule", unschedulePlaylist(s))
+					one.Patch("/{id}/webhook", webhookPlaylist(s))
+
+					one.Post("/{id}/song", addSong(s))
+					one.Get("/{id}/songs", songsPage(s))
+					one.Put("/{id}/songs", replaceSongs(s))
+					one.Patch("/{id}/song/{sid}", editSong(s))
+					one.Post("/{id}/song/{sid}/move", moveSong(s))
+					one.Post("/{id}/swap", swapSongs(s))
+					one.Post("/{id}/song/{sid}/transfer", transferSong(s))
+					one.Post("/{id}/song/{sid}/play", playSong(s))
+					one.Delete("/{id}/song/{sid}", removeSong(s))
+					one.Post("/{id}/song/{sid}/favorite", favoriteSong(s, true))
+					one.Delete("/{id}/song/{sid}/favorite", favoriteSong(s, false))
+				})
+			})
 		})
 	})
 
@@ -75,6 +184,60 @@ func ping(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func version(w http.ResponseWriter, r *http.Request) {
+	render.Render(w, r, &versionResponse{
+		HTTPStatusCode: http.StatusOK,
+		Info:           build.Get(),
+	})
+}
+
+func health(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), healthTimeout)
+		defer cancel()
+
+		latency, err := s.HealthCheck(ctx)
+		if err != nil {
+			render.Render(w, r, &healthResponse{
+				HTTPStatusCode: http.StatusServiceUnavailable,
+				Database:       "down",
+				Latency:        float64(latency.Microseconds()) / 1000,
+				ErrorText:      err.Error(),
+			})
+
+			return
+		}
+
+		render.Render(w, r, &healthResponse{
+			HTTPStatusCode: http.StatusOK,
+			Database:       "up",
+			Latency:        float64(latency.Microseconds()) / 1000,
+		})
+	}
+}
+
+func requestBodyLimit(max int64) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if max > 0 && r.Body != nil {
+				r.Body = http.MaxBytesReader(w, r.Body, max)
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+func playlistLocation(id uint) string {
+	return fmt.Sprintf("/v1/playlist/%d", id)
+}
+
+func sharedLocation(token string) string {
+	return "/v1/shared/" + token
+}
+
 func parseId(r *http.Request, s string) (uint, error) {
 	id, err := strconv.ParseUint(chi.URLParam(r, s), 10, 32)
 	if err != nil {
@@ -84,25 +247,171 @@ func parseId(r *http.Request, s string) (uint, error) {
 	return uint(id), nil
 }
 
+func parseQueryInt(r *http.Request, key string, fallback int) (int, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	return strconv.Atoi(value)
+}
+
+func parseQueryBool(r *http.Request, key string, fallback bool) (bool, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	return strconv.ParseBool(value)
+}
+
 func getAll(s *service.Service) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var pls []playlistData
+		limit, err := parseQueryInt(r, "limit", defaultLimit)
+		if err != nil || limit < 1 {
+			render.Render(w, r, responseInvalidRequest(ErrInvalidLimit))
+
+			return
+		}
+
+		offset, err := parseQueryInt(r, "offset", 0)
+		if err != nil || offset < 0 {
+			render.Render(w, r, responseInvalidRequest(ErrInvalidOffset))
+
+			return
+		}
+
+		fields, err := parseFields(r)
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		expand, err := parseExpand(r, false)
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
 
-		for _, pl := range s.GetPlaylists() {
-			pls = append(pls, playlistData{
-				Status: pl.Status(),
-				Songs:  pl.GetSongsList(),
+		userId, restricted := ownerFilter(r)
+
+		opts := service.ListOptions{
+			Name:       r.URL.Query().Get("name"),
+			Owner:      userId,
+			Restricted: restricted,
+		}
+
+		if status := r.URL.Query().Get("status"); status != "" {
+			if opts.Status, err = service.ParseState(status); err != nil {
+				render.Render(w, r, responseInvalidRequest(err))
+
+				return
+			}
+		}
+
+		if sort := r.URL.Query().Get("sort"); sort != "" {
+			if opts.Sort, err = service.ParseSort(sort); err != nil {
+				render.Render(w, r, responseInvalidRequest(err))
+
+				return
+			}
+		}
+
+		found, err := s.ListPlaylists(opts)
+		if err != nil {
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "getAll", err)
+
+			return
+		}
+
+		page, total := service.Page(found, offset, limit), len(found)
+
+		if render.GetAcceptedContentType(r) != render.ContentTypeJSON {
+			var pls []playlistData
+
+			for _, pl := range page {
+				pls = append(pls, selectPlaylistData(pl, fields, expand, baseUrl(r)))
+			}
+
+			render.Render(w, r, &allResponse{
+				HTTPStatusCode: http.StatusOK,
+				Total:          total,
+				Playlists:      pls,
+				offset:         offset,
+				limit:          limit,
 			})
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+
+		pw, err := newPlaylistsWriter(w, total, fields, expand, baseUrl(r))
+		if err != nil {
+			logError(s, r, "getAll", err)
+
+			return
+		}
+
+		for _, pl := range page {
+			if err := pw.WritePlaylist(pl); err != nil {
+				logError(s, r, "getAll", err)
+
+				return
+			}
+		}
+
+		if err := pw.Close(); err != nil {
+			logError(s, r, "getAll", err)
+		}
+	}
+}
+
+func getPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		fields, err := parseFields(r)
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		expand, err := parseExpand(r, true)
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		pl, err := s.GetPlaylist(id)
+		if err != nil {
+			render.Render(w, r, responseError(err))
+
+			return
 		}
 
-		render.Render(w, r, &allResponse{
+		w.Header().Set("ETag", etag(pl.Version()))
+
+		render.Render(w, r, &playlistResponse{
 			HTTPStatusCode: http.StatusOK,
-			Playlists:      pls,
+			Playlist:       selectPlaylistData(pl, fields, expand, baseUrl(r)),
 		})
 	}
 }
 
-func getPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+func exportPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id, err := parseId(r, "id")
 		if err != nil {
@@ -111,74 +420,1743 @@ func getPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
 			return
 		}
 
-		pl, err := s.GetPlaylist(id)
-		if err != nil {
-			render.Render(w, r, responseInternalError(err))
+		format := r.URL.Query().Get("format")
+		if format != "" && format != "json" && format != "m3u" {
+			render.Render(w, r, responseInvalidRequest(ErrInvalidFormat))
+
+			return
+		}
+
+		if format != "m3u" {
+			getPlaylist(s)(w, r)
+
+			return
+		}
+
+		pl, sns, err := s.ExportPlaylist(id)
+		if err != nil {
+			render.Render(w, r, responseError(err))
+
+			return
+		}
+
+		w.Header().Set("Content-Type", export.M3UContentType)
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+			"filename": pl.Name + export.M3UExtension,
+		}))
+
+		if err := export.M3U(w, pl, sns); err != nil {
+			logError(s, r, "exportPlaylist", err)
+		}
+	}
+}
+
+func importPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			render.Render(w, r, responseDecodeError(err))
+
+			return
+		}
+		defer file.Close()
+
+		name := r.FormValue("name")
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(header.Filename), filepath.Ext(header.Filename))
+		}
+
+		id, err := s.ImportPlaylist(r.Context(), name, file)
+		if err != nil {
+			var parseErr *export.ParseError
+
+			if errors.As(err, &parseErr) {
+				render.Render(w, r, responseInvalidRequest(err))
+
+				return
+			}
+
+			if errors.Is(err, service.ErrNoSongs) || errors.Is(err, service.ErrInvalidName) || errors.Is(err, service.ErrInvalidDuration) {
+				render.Render(w, r, responseUnprocessable(err))
+
+				return
+			}
+
+			if errors.Is(err, service.ErrDuplicateName) {
+				render.Render(w, r, responseConflict(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "importPlaylist", err)
+
+			return
+		}
+
+		created, err := s.GetPlaylist(id)
+		if err != nil {
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "importPlaylist", err)
+
+			return
+		}
+
+		w.Header().Set("Location", playlistLocation(id))
+
+		render.Render(w, r, &playlistResponse{
+			HTTPStatusCode: http.StatusCreated,
+			Playlist:       newPlaylistData(created),
+		})
+	}
+}
+
+func newPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			render.Render(w, r, responseDecodeError(err))
+
+			return
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.DisallowUnknownFields()
+
+		var data struct {
+			Name  string
+			Songs []database.Song
+		}
+
+		err = dec.Decode(&data)
+		if err != nil {
+			render.Render(w, r, responseDecodeError(err))
+
+			return
+		}
+
+		if err := service.ValidatePlaylist(data.Name, data.Songs); err != nil {
+			render.Render(w, r, responseValidation(err))
+
+			return
+		}
+
+		create := func() (uint, error) {
+			var pl database.Playlist
+			pl.Name = data.Name
+
+			if err := s.CreatePlaylistWithSongs(r.Context(), &pl, data.Songs); err != nil {
+				return 0, err
+			}
+
+			return pl.Id, nil
+		}
+
+		var userId string
+
+		if claims, ok := auth.FromContext(r.Context()); ok {
+			userId = claims.UserId
+		}
+
+		id, replayed, err := s.Idempotent(userId, r.Header.Get("Idempotency-Key"), body, create)
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidName) {
+				render.Render(w, r, responseUnprocessable(err))
+
+				return
+			}
+
+			if errors.Is(err, service.ErrIdempotencyMismatch) {
+				render.Render(w, r, responseUnprocessable(err))
+
+				return
+			}
+
+			if errors.Is(err, service.ErrDuplicateName) {
+				render.Render(w, r, responseConflict(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "newPlaylist", err)
+
+			return
+		}
+
+		created, err := s.GetPlaylist(id)
+		if err != nil {
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "newPlaylist", err)
+
+			return
+		}
+
+		if replayed {
+			w.Header().Set("Idempotent-Replayed", "true")
+		}
+
+		w.Header().Set("Location", playlistLocation(id))
+
+		render.Render(w, r, &playlistResponse{
+			HTTPStatusCode: http.StatusCreated,
+			Playlist:       newPlaylistData(created),
+		})
+	}
+}
+
+func deletePlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		_, err = s.GetPlaylist(id)
+		if err != nil {
+			render.Render(w, r, responseError(err))
+
+			return
+		}
+
+		if err := s.DeletePlaylist(r.Context(), id); err != nil {
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "deletePlaylist", err)
+
+			return
+		}
+
+		render.Render(w, r, &messageResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "playlist deleted",
+			PlaylistId:     id,
+		})
+	}
+}
+
+func restorePlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		userId, restricted := ownerFilter(r)
+
+		if err := s.RestoreDeleted(r.Context(), id, userId, restricted); err != nil {
+			if errors.Is(err, service.ErrPlaylistNotFound) {
+				render.Render(w, r, responseNotFoundError(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "restorePlaylist", err)
+
+			return
+		}
+
+		render.Render(w, r, &messageResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "playlist restored",
+			PlaylistId:     id,
+		})
+	}
+}
+
+func purgePlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		if err := s.PurgePlaylist(r.Context(), id); err != nil {
+			if errors.Is(err, service.ErrPlaylistNotFound) {
+				render.Render(w, r, responseNotFoundError(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "purgePlaylist", err)
+
+			return
+		}
+
+		render.Render(w, r, &messageResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "playlist purged",
+			PlaylistId:     id,
+		})
+	}
+}
+
+func clonePlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+
+		var data struct{ Name string }
+
+		err := dec.Decode(&data)
+		if err != nil && !errors.Is(err, io.EOF) {
+			render.Render(w, r, responseDecodeError(err))
+
+			return
+		}
+
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		pl, err := s.ClonePlaylist(r.Context(), id, data.Name)
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidName) {
+				render.Render(w, r, responseUnprocessable(err))
+
+				return
+			}
+
+			if errors.Is(err, service.ErrDuplicateName) {
+				render.Render(w, r, responseConflict(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "clonePlaylist", err)
+
+			return
+		}
+
+		w.Header().Set("Location", playlistLocation(pl.Id))
+
+		render.Render(w, r, &messageResponse{
+			HTTPStatusCode: http.StatusCreated,
+			MessageText:    "playlist cloned",
+			PlaylistId:     pl.Id,
+		})
+	}
+}
+
+func addSong(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+
+		var data []database.Song
+
+		err := dec.Decode(&data)
+		if err != nil {
+			render.Render(w, r, responseDecodeError(err))
+
+			return
+		}
+
+		if len(data) < 1 {
+			render.Render(w, r, responseInvalidRequest(ErrNoSongsProvided))
+
+			return
+		}
+
+		if err := service.ValidateSongs(data); err != nil {
+			render.Render(w, r, responseUnprocessable(err))
+
+			return
+		}
+
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		position, err := parseQueryInt(r, "position", -1)
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(ErrInvalidPosition))
+
+			return
+		}
+
+		dedup, err := parseQueryBool(r, "dedup", false)
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(ErrInvalidDedup))
+
+			return
+		}
+
+		pl, err := s.GetPlaylist(id)
+		if err != nil {
+			render.Render(w, r, responseError(err))
+
+			return
+		}
+
+		var skipped int
+
+		if dedup {
+			data, skipped = service.DedupSongs(pl.GetSongsList(), data)
+		}
+
+		if len(data) == 0 {
+			render.Render(w, r, &songsResponse{
+				HTTPStatusCode: http.StatusOK,
+				PlaylistId:     id,
+				Skipped:        skipped,
+			})
+
+			return
+		}
+
+		if err := s.AddSongs(r.Context(), id, data, position); err != nil {
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "addSong", err)
+
+			return
+		}
+
+		var songs []playlist.Song
+
+		for _, sn := range data {
+			song, err := pl.GetSong(sn.SongId)
+			if err != nil {
+				render.Render(w, r, responseError(err))
+
+				logError(s, r, "addSong", err)
+
+				return
+			}
+
+			songs = append(songs, song)
+		}
+
+		w.Header().Set("Location", playlistLocation(id))
+
+		render.Render(w, r, &songsResponse{
+			HTTPStatusCode: http.StatusCreated,
+			PlaylistId:     id,
+			Songs:          songs,
+			Skipped:        skipped,
+		})
+	}
+}
+
+func replaceSongs(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+
+		var data []database.Song
+
+		err := dec.Decode(&data)
+		if err != nil {
+			render.Render(w, r, responseDecodeError(err))
+
+			return
+		}
+
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		if err := s.ReplaceSongs(r.Context(), id, data); err != nil {
+			if errors.Is(err, service.ErrPlaylistLaunched) {
+				render.Render(w, r, responseConflict(err))
+
+				return
+			}
+
+			if errors.Is(err, service.ErrInvalidDuration) {
+				render.Render(w, r, responseUnprocessable(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "replaceSongs", err)
+
+			return
+		}
+
+		render.Render(w, r, &countResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "songs replaced",
+			PlaylistId:     id,
+			Count:          len(data),
+		})
+	}
+}
+
+func editSong(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+
+		var data database.Song
+
+		err := dec.Decode(&data)
+		if err != nil {
+			render.Render(w, r, responseDecodeError(err))
+
+			return
+		}
+
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		sid, err := parseId(r, "sid")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		if err := s.EditSong(r.Context(), id, sid, data.Name, uint(data.Duration), data.Tags); err != nil {
+			if isNotFound(err) {
+				render.Render(w, r, responseNotFoundError(err))
+
+				return
+			}
+
+			if errors.Is(err, service.ErrInvalidDuration) {
+				render.Render(w, r, responseUnprocessable(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "editSong", err)
+
+			return
+		}
+
+		render.Render(w, r, &messageResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "song updated",
+			PlaylistId:     id,
+		})
+	}
+}
+
+func moveSong(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+
+		var data struct{ Position int }
+
+		err := dec.Decode(&data)
+		if err != nil {
+			render.Render(w, r, responseDecodeError(err))
+
+			return
+		}
+
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		sid, err := parseId(r, "sid")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		if err := s.MoveSong(r.Context(), id, sid, data.Position); err != nil {
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "moveSong", err)
+
+			return
+		}
+
+		render.Render(w, r, &messageResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "song moved",
+			PlaylistId:     id,
+		})
+	}
+}
+
+func swapSongs(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+
+		var data struct{ A, B uint }
+
+		err := dec.Decode(&data)
+		if err != nil {
+			render.Render(w, r, responseDecodeError(err))
+
+			return
+		}
+
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		if err := s.SwapSongs(r.Context(), id, data.A, data.B); err != nil {
+			if errors.Is(err, playlist.ErrSwapSame) {
+				render.Render(w, r, responseUnprocessable(err))
+
+				return
+			}
+
+			if isNotFound(err) {
+				render.Render(w, r, responseNotFoundError(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "swapSongs", err)
+
+			return
+		}
+
+		render.Render(w, r, &messageResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "songs swapped",
+			PlaylistId:     id,
+		})
+	}
+}
+
+func transferSong(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+
+		var data struct{ Target uint }
+
+		err := dec.Decode(&data)
+		if err != nil {
+			render.Render(w, r, responseDecodeError(err))
+
+			return
+		}
+
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		sid, err := parseId(r, "sid")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		if target, err := s.GetPlaylist(data.Target); err == nil && !canAccess(r, target) {
+			render.Render(w, r, responseForbidden(ErrForbidden))
+
+			return
+		}
+
+		if err := s.TransferSong(r.Context(), id, sid, data.Target); err != nil {
+			if errors.Is(err, service.ErrSameTarget) {
+				render.Render(w, r, responseInvalidRequest(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "transferSong", err)
+
+			return
+		}
+
+		render.Render(w, r, &messageResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "song transferred",
+			PlaylistId:     data.Target,
+		})
+	}
+}
+
+func playSong(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		sid, err := parseId(r, "sid")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		pl, err := s.GetPlaylist(id)
+		if err != nil {
+			render.Render(w, r, responseError(err))
+
+			return
+		}
+
+		if err = pl.PlaySong(sid); err != nil {
+			if errors.Is(err, playlist.ErrSongNotIn) {
+				render.Render(w, r, responseNotFoundError(err))
+
+				return
+			}
+
+			if isStateConflict(err) {
+				render.Render(w, r, responseConflict(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "playSong", err)
+
+			return
+		}
+
+		render.Render(w, r, &messageResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "playlist switched to song",
+			PlaylistId:     id,
+		})
+	}
+}
+
+func removeSong(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		sid, err := parseId(r, "sid")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		if err := s.DeleteSong(r.Context(), id, sid); err != nil {
+			if isNotFound(err) {
+				render.Render(w, r, responseNotFoundError(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "removeSong", err)
+
+			return
+		}
+
+		render.Render(w, r, &messageResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "song removed",
+			PlaylistId:     id,
+		})
+	}
+}
+
+func favoriteSong(s *service.Service, favorite bool) func(http.ResponseWriter, *http.Request) {
+	message := "song removed from favorites"
+	if favorite {
+		message = "song added to favorites"
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		sid, err := parseId(r, "sid")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		if err := s.SetFavorite(r.Context(), id, sid, favorite); err != nil {
+			if isNotFound(err) {
+				render.Render(w, r, responseNotFoundError(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "favoriteSong", err)
+
+			return
+		}
+
+		render.Render(w, r, &messageResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    message,
+			PlaylistId:     id,
+		})
+	}
+}
+
+func playPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		pl, err := s.GetPlaylist(id)
+		if err != nil {
+			render.Render(w, r, responseError(err))
+
+			return
+		}
+
+		if err = pl.Play(); err != nil {
+			if errors.Is(err, service.ErrNoSongs) {
+				render.Render(w, r, responseUnprocessable(err))
+
+				return
+			}
+
+			if isStateConflict(err) {
+				render.Render(w, r, responseConflict(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "playPlaylist", err)
+
+			return
+		}
+
+		render.Render(w, r, &messageResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "playlist is playing",
+			PlaylistId:     id,
+		})
+	}
+}
+
+func pausePlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		pl, err := s.GetPlaylist(id)
+		if err != nil {
+			render.Render(w, r, responseError(err))
+
+			return
+		}
+
+		if err = pl.Pause(); err != nil {
+			if isStateConflict(err) {
+				render.Render(w, r, responseConflict(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "pausePlaylist", err)
+
+			return
+		}
+
+		render.Render(w, r, &messageResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "playlist paused",
+			PlaylistId:     id,
+		})
+	}
+}
+
+func nextPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		pl, err := s.GetPlaylist(id)
+		if err != nil {
+			render.Render(w, r, responseError(err))
+
+			return
+		}
+
+		if err = pl.Next(); err != nil {
+			if errors.Is(err, service.ErrNoSongs) {
+				render.Render(w, r, responseUnprocessable(err))
+
+				return
+			}
+
+			if errors.Is(err, service.ErrEndOfPlaylist) {
+				render.Render(w, r, responseConflict(err))
+
+				return
+			}
+
+			if isStateConflict(err) {
+				render.Render(w, r, responseConflict(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "nextPlaylist", err)
+
+			return
+		}
+
+		render.Render(w, r, &messageResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "playlist switched to next song",
+			PlaylistId:     id,
+		})
+	}
+}
+
+func prevPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		pl, err := s.GetPlaylist(id)
+		if err != nil {
+			render.Render(w, r, responseError(err))
+
+			return
+		}
+
+		if err = pl.Prev(); err != nil {
+			if errors.Is(err, service.ErrNoSongs) {
+				render.Render(w, r, responseUnprocessable(err))
+
+				return
+			}
+
+			if errors.Is(err, service.ErrStartOfPlaylist) {
+				render.Render(w, r, responseConflict(err))
+
+				return
+			}
+
+			if isStateConflict(err) {
+				render.Render(w, r, responseConflict(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "prevPlaylist", err)
+
+			return
+		}
+
+		render.Render(w, r, &messageResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "playlist switched to prev song",
+			PlaylistId:     id,
+		})
+	}
+}
+
+func seekPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+
+		var data struct{ Index int }
+
+		err := dec.Decode(&data)
+		if err != nil {
+			render.Render(w, r, responseDecodeError(err))
+
+			return
+		}
+
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		pl, err := s.GetPlaylist(id)
+		if err != nil {
+			render.Render(w, r, responseError(err))
+
+			return
+		}
+
+		if err = pl.SeekIndex(data.Index); err != nil {
+			if errors.Is(err, playlist.ErrIndexOutOfRange) {
+				render.Render(w, r, responseInvalidRequest(err))
+
+				return
+			}
+
+			if isStateConflict(err) {
+				render.Render(w, r, responseConflict(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "seekPlaylist", err)
+
+			return
+		}
+
+		render.Render(w, r, &messageResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "playlist switched to index",
+			PlaylistId:     id,
+		})
+	}
+}
+
+func restartPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		sn, err := s.RestartPlaylist(id)
+		if err != nil {
+			if errors.Is(err, service.ErrNoSongs) {
+				render.Render(w, r, responseUnprocessable(err))
+
+				return
+			}
+
+			if isStateConflict(err) {
+				render.Render(w, r, responseConflict(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "restartPlaylist", err)
+
+			return
+		}
+
+		render.Render(w, r, &songResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "playlist restarted",
+			PlaylistId:     id,
+			Song:           sn,
+		})
+	}
+}
+
+func queueSong(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+
+		var data struct{ Song uint }
+
+		err := dec.Decode(&data)
+		if err != nil {
+			render.Render(w, r, responseDecodeError(err))
+
+			return
+		}
+
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		pl, err := s.GetPlaylist(id)
+		if err != nil {
+			render.Render(w, r, responseError(err))
+
+			return
+		}
+
+		if err = pl.Enqueue(data.Song); err != nil {
+			if errors.Is(err, playlist.ErrSongNotIn) {
+				render.Render(w, r, responseNotFoundError(err))
+
+				return
+			}
+
+			if errors.Is(err, playlist.ErrNotProcessed) || errors.Is(err, playlist.ErrQueueFull) {
+				render.Render(w, r, responseConflict(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "queueSong", err)
+
+			return
+		}
+
+		render.Render(w, r, &messageResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "song queued",
+			PlaylistId:     id,
+		})
+	}
+}
+
+func launchPlaylist(ctx context.Context, s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		_, err = s.GetPlaylist(id)
+		if err != nil {
+			render.Render(w, r, responseError(err))
+
+			return
+		}
+
+		if err = s.LaunchPlaylist(ctx, id, trace.LinkFromContext(r.Context())); err != nil {
+			if errors.Is(err, service.ErrNoSongs) {
+				render.Render(w, r, responseUnprocessable(err))
+
+				return
+			}
+
+			if errors.Is(err, service.ErrAlreadyLaunched) {
+				render.Render(w, r, responseConflict(err))
+
+				return
+			}
+
+			if errors.Is(err, service.ErrLaunchLimit) {
+				render.Render(w, r, responseTooManyRequests(err))
+
+				return
+			}
+
+			if errors.Is(err, service.ErrShuttingDown) {
+				render.Render(w, r, responseServiceUnavailable(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "launchPlaylist", err)
+
+			return
+		}
+
+		render.Render(w, r, &messageResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "playlist is processing",
+			PlaylistId:     id,
+		})
+	}
+}
+
+func stopPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		_, err = s.GetPlaylist(id)
+		if err != nil {
+			render.Render(w, r, responseError(err))
+
+			return
+		}
+
+		if err = s.StopLaunch(id); err != nil {
+			if isStateConflict(err) {
+				render.Render(w, r, responseConflict(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "stopPlaylist", err)
+
+			return
+		}
+
+		render.Render(w, r, &messageResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "playlist stopped",
+			PlaylistId:     id,
+		})
+	}
+}
+
+func namePlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+
+		var data struct{ Name string }
+
+		err := dec.Decode(&data)
+		if err != nil {
+			render.Render(w, r, responseDecodeError(err))
+
+			return
+		}
+
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		_, err = s.GetPlaylist(id)
+		if err != nil {
+			render.Render(w, r, responseError(err))
+
+			return
+		}
+
+		if err = s.EditPlaylist(r.Context(), id, data.Name); err != nil {
+			if errors.Is(err, service.ErrInvalidName) {
+				render.Render(w, r, responseUnprocessable(err))
+
+				return
+			}
+
+			if errors.Is(err, service.ErrDuplicateName) {
+				render.Render(w, r, responseConflict(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "namePlaylist", err)
+
+			return
+		}
+
+		render.Render(w, r, &messageResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "playlist renamed",
+			PlaylistId:     id,
+		})
+	}
+}
+
+func timePlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+
+		var data struct{ Time uint }
+
+		err := dec.Decode(&data)
+		if err != nil {
+			render.Render(w, r, responseDecodeError(err))
+
+			return
+		}
+
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		pl, err := s.GetPlaylist(id)
+		if err != nil {
+			render.Render(w, r, responseError(err))
+
+			return
+		}
+
+		if err = pl.SetTime(data.Time); err != nil {
+			if errors.Is(err, service.ErrNoSongs) {
+				render.Render(w, r, responseUnprocessable(err))
+
+				return
+			}
+
+			if isStateConflict(err) {
+				render.Render(w, r, responseConflict(err))
+
+				return
+			}
+
+			if errors.Is(err, playlist.ErrLargerTime) {
+				render.Render(w, r, responseUnprocessable(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "timePlaylist", err)
+
+			return
+		}
+
+		if err = s.BumpVersion(r.Context(), id); err != nil {
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "timePlaylist", err)
+
+			return
+		}
+
+		render.Render(w, r, &messageResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "playlist time set",
+			PlaylistId:     id,
+		})
+	}
+}
+
+func elapsedPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		pl, err := s.GetPlaylist(id)
+		if err != nil {
+			render.Render(w, r, responseError(err))
+
+			return
+		}
+
+		elapsed, duration := pl.Elapsed()
+
+		render.Render(w, r, &timeResponse{
+			HTTPStatusCode: http.StatusOK,
+			Elapsed:        elapsed,
+			Duration:       duration,
+		})
+	}
+}
+
+func remainingPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		pl, err := s.GetPlaylist(id)
+		if err != nil {
+			render.Render(w, r, responseError(err))
+
+			return
+		}
+
+		render.Render(w, r, &remainingResponse{
+			HTTPStatusCode: http.StatusOK,
+			Remaining:      pl.Remaining(),
+		})
+	}
+}
+
+func shufflePlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+
+		var data struct{ Shuffle bool }
+
+		err := dec.Decode(&data)
+		if err != nil {
+			render.Render(w, r, responseDecodeError(err))
+
+			return
+		}
+
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		pl, err := s.GetPlaylist(id)
+		if err != nil {
+			render.Render(w, r, responseError(err))
+
+			return
+		}
+
+		if err = pl.SetShuffle(data.Shuffle); err != nil {
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "shufflePlaylist", err)
+
+			return
+		}
+
+		if err = s.BumpVersion(r.Context(), id); err != nil {
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "shufflePlaylist", err)
+
+			return
+		}
+
+		render.Render(w, r, &messageResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "playlist shuffle set",
+			PlaylistId:     id,
+		})
+	}
+}
+
+func repeatPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+
+		var data struct{ Mode playlist.Repeat }
+
+		err := dec.Decode(&data)
+		if err != nil {
+			render.Render(w, r, responseDecodeError(err))
+
+			return
+		}
+
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		pl, err := s.GetPlaylist(id)
+		if err != nil {
+			render.Render(w, r, responseError(err))
+
+			return
+		}
+
+		if err = pl.SetRepeat(data.Mode); err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		if err = s.BumpVersion(r.Context(), id); err != nil {
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "repeatPlaylist", err)
+
+			return
+		}
+
+		render.Render(w, r, &messageResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "playlist repeat set",
+			PlaylistId:     id,
+		})
+	}
+}
+
+func speedPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+
+		var data struct{ Speed float64 }
+
+		err := dec.Decode(&data)
+		if err != nil {
+			render.Render(w, r, responseDecodeError(err))
+
+			return
+		}
+
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		pl, err := s.GetPlaylist(id)
+		if err != nil {
+			render.Render(w, r, responseError(err))
+
+			return
+		}
+
+		if err = pl.SetSpeed(data.Speed); err != nil {
+			render.Render(w, r, responseUnprocessable(err))
+
+			return
+		}
+
+		if err = s.BumpVersion(r.Context(), id); err != nil {
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "speedPlaylist", err)
+
+			return
+		}
+
+		render.Render(w, r, &messageResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "playlist speed set",
+			PlaylistId:     id,
+		})
+	}
+}
+
+func volumePlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+
+		var data struct{ Volume int }
+
+		err := dec.Decode(&data)
+		if err != nil {
+			render.Render(w, r, responseDecodeError(err))
+
+			return
+		}
+
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		if err := s.SetVolume(r.Context(), id, data.Volume); err != nil {
+			if errors.Is(err, service.ErrInvalidVolume) {
+				render.Render(w, r, responseUnprocessable(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "volumePlaylist", err)
+
+			return
+		}
+
+		render.Render(w, r, &messageResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "playlist volume set",
+			PlaylistId:     id,
+		})
+	}
+}
+
+func sleepPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+
+		var data struct{ Minutes uint }
+
+		err := dec.Decode(&data)
+		if err != nil {
+			render.Render(w, r, responseDecodeError(err))
+
+			return
+		}
+
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		at, err := s.SleepPlaylist(id, data.Minutes)
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidSleep) {
+				render.Render(w, r, responseUnprocessable(err))
+
+				return
+			}
+
+			if errors.Is(err, playlist.ErrNotProcessed) {
+				render.Render(w, r, responseConflict(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "sleepPlaylist", err)
+
+			return
+		}
+
+		if at.IsZero() {
+			render.Render(w, r, &sleepResponse{
+				HTTPStatusCode: http.StatusOK,
+				MessageText:    "sleep timer cancelled",
+				PlaylistId:     id,
+			})
 
 			return
 		}
 
-		render.Render(w, r, &playlistResponse{
+		render.Render(w, r, &sleepResponse{
 			HTTPStatusCode: http.StatusOK,
-			Playlist: playlistData{
-				Status: pl.Status(),
-				Songs:  pl.GetSongsList(),
-			},
+			MessageText:    "sleep timer set",
+			PlaylistId:     id,
+			StopAt:         &at,
 		})
 	}
 }
 
-func newPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+func schedulePlaylist(ctx context.Context, s *service.Service) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		dec := json.NewDecoder(r.Body)
 		dec.DisallowUnknownFields()
 
-		var data struct {
-			Name  string
-			Songs []database.Song
-		}
+		var data struct{ At time.Time }
 
 		err := dec.Decode(&data)
 		if err != nil {
-			render.Render(w, r, responseInvalidRequest(ErrRequestBody))
+			render.Render(w, r, responseDecodeError(err))
 
 			return
 		}
 
-		var pl database.Playlist
-		pl.Name = data.Name
-
-		if err := s.CreatePlaylist(&pl); err != nil {
-			render.Render(w, r, responseInternalError(err))
-
-			s.ChanErrorLog <- err
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
 
 			return
 		}
 
-		id := pl.Id
+		if err := s.SchedulePlaylist(ctx, id, data.At); err != nil {
+			if errors.Is(err, service.ErrScheduleInPast) {
+				render.Render(w, r, responseInvalidRequest(err))
 
-		for _, sn := range data.Songs {
-			sn.PlaylistId = id
-
-			if err := s.CreateSong(&sn); err != nil {
-				render.Render(w, r, responseInternalError(err))
+				return
+			}
 
-				s.ChanErrorLog <- err
+			if errors.Is(err, service.ErrShuttingDown) {
+				render.Render(w, r, responseServiceUnavailable(err))
 
 				return
 			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "schedulePlaylist", err)
+
+			return
 		}
 
-		render.Render(w, r, &messageResponse{
-			HTTPStatusCode: http.StatusCreated,
-			MessageText:    "playlist created",
+		render.Render(w, r, &scheduleResponse{
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    "playlist launch scheduled",
 			PlaylistId:     id,
+			LaunchAt:       data.At.UTC(),
 		})
 	}
 }
 
-func deletePlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+func unschedulePlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id, err := parseId(r, "id")
 		if err != nil {
@@ -187,45 +2165,38 @@ func deletePlaylist(s *service.Service) func(http.ResponseWriter, *http.Request)
 			return
 		}
 
-		_, err = s.GetPlaylist(id)
-		if err != nil {
-			render.Render(w, r, responseInternalError(err))
+		if err := s.UnschedulePlaylist(id); err != nil {
+			if errors.Is(err, service.ErrScheduleNotFound) {
+				render.Render(w, r, responseNotFoundError(err))
 
-			return
-		}
+				return
+			}
 
-		if err := s.DeletePlaylist(id); err != nil {
-			render.Render(w, r, responseInternalError(err))
+			render.Render(w, r, responseError(err))
 
-			s.ChanErrorLog <- err
+			logError(s, r, "unschedulePlaylist", err)
 
 			return
 		}
 
 		render.Render(w, r, &messageResponse{
 			HTTPStatusCode: http.StatusOK,
-			MessageText:    "playlist deleted",
+			MessageText:    "playlist launch unscheduled",
 			PlaylistId:     id,
 		})
 	}
 }
 
-func addSong(s *service.Service) func(http.ResponseWriter, *http.Request) {
+func webhookPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		dec := json.NewDecoder(r.Body)
 		dec.DisallowUnknownFields()
 
-		var data []database.Song
+		var data struct{ Url string }
 
 		err := dec.Decode(&data)
 		if err != nil {
-			render.Render(w, r, responseInvalidRequest(ErrRequestBody))
-
-			return
-		}
-
-		if len(data) < 1 {
-			render.Render(w, r, responseInvalidRequest(ErrNoSongsProvided))
+			render.Render(w, r, responseDecodeError(err))
 
 			return
 		}
@@ -237,47 +2208,35 @@ func addSong(s *service.Service) func(http.ResponseWriter, *http.Request) {
 			return
 		}
 
-		_, err = s.GetPlaylist(id)
-		if err != nil {
-			render.Render(w, r, responseInternalError(err))
+		if err := s.SetWebhook(r.Context(), id, data.Url); err != nil {
+			if errors.Is(err, service.ErrInvalidWebhook) {
+				render.Render(w, r, responseUnprocessable(err))
 
-			return
-		}
+				return
+			}
 
-		for _, sn := range data {
-			sn.PlaylistId = id
+			render.Render(w, r, responseError(err))
 
-			if err := s.CreateSong(&sn); err != nil {
-				render.Render(w, r, responseInternalError(err))
+			logError(s, r, "webhookPlaylist", err)
 
-				s.ChanErrorLog <- err
+			return
+		}
 
-				return
-			}
+		message := "playlist webhook set"
+		if data.Url == "" {
+			message = "playlist webhook cleared"
 		}
 
 		render.Render(w, r, &messageResponse{
-			HTTPStatusCode: http.StatusCreated,
-			MessageText:    "songs added",
+			HTTPStatusCode: http.StatusOK,
+			MessageText:    message,
 			PlaylistId:     id,
 		})
 	}
 }
 
-func editSong(s *service.Service) func(http.ResponseWriter, *http.Request) {
+func sharePlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		dec := json.NewDecoder(r.Body)
-		dec.DisallowUnknownFields()
-
-		var data database.Song
-
-		err := dec.Decode(&data)
-		if err != nil {
-			render.Render(w, r, responseInvalidRequest(ErrRequestBody))
-
-			return
-		}
-
 		id, err := parseId(r, "id")
 		if err != nil {
 			render.Render(w, r, responseInvalidRequest(err))
@@ -285,30 +2244,27 @@ func editSong(s *service.Service) func(http.ResponseWriter, *http.Request) {
 			return
 		}
 
-		sid, err := parseId(r, "sid")
+		token, err := s.SharePlaylist(r.Context(), id)
 		if err != nil {
-			render.Render(w, r, responseInvalidRequest(err))
-
-			return
-		}
+			render.Render(w, r, responseError(err))
 
-		if err := s.EditSong(id, sid, data.Name, data.Duration); err != nil {
-			render.Render(w, r, responseInternalError(err))
-
-			s.ChanErrorLog <- err
+			logError(s, r, "sharePlaylist", err)
 
 			return
 		}
 
-		render.Render(w, r, &messageResponse{
-			HTTPStatusCode: http.StatusOK,
-			MessageText:    "song updated",
+		w.Header().Set("Location", sharedLocation(token))
+
+		render.Render(w, r, &shareResponse{
+			HTTPStatusCode: http.StatusCreated,
 			PlaylistId:     id,
+			Token:          token,
+			Url:            sharedLocation(token),
 		})
 	}
 }
 
-func removeSong(s *service.Service) func(http.ResponseWriter, *http.Request) {
+func unsharePlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id, err := parseId(r, "id")
 		if err != nil {
@@ -317,126 +2273,185 @@ func removeSong(s *service.Service) func(http.ResponseWriter, *http.Request) {
 			return
 		}
 
-		sid, err := parseId(r, "sid")
-		if err != nil {
-			render.Render(w, r, responseInvalidRequest(err))
+		if err := s.UnsharePlaylist(r.Context(), id); err != nil {
+			if errors.Is(err, service.ErrNotShared) {
+				render.Render(w, r, responseNotFoundError(err))
 
-			return
-		}
+				return
+			}
 
-		if err := s.DeleteSong(id, sid); err != nil {
-			render.Render(w, r, responseInternalError(err))
+			render.Render(w, r, responseError(err))
 
-			s.ChanErrorLog <- err
+			logError(s, r, "unsharePlaylist", err)
 
 			return
 		}
 
 		render.Render(w, r, &messageResponse{
 			HTTPStatusCode: http.StatusOK,
-			MessageText:    "song removed",
+			MessageText:    "share revoked",
 			PlaylistId:     id,
 		})
 	}
 }
 
-func playPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+func sharedPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		id, err := parseId(r, "id")
+		pl, err := s.GetSharedPlaylist(chi.URLParam(r, "token"))
 		if err != nil {
-			render.Render(w, r, responseInvalidRequest(err))
+			render.Render(w, r, responseNotFoundError(err))
 
 			return
 		}
 
-		pl, err := s.GetPlaylist(id)
+		render.Render(w, r, &playlistResponse{
+			HTTPStatusCode: http.StatusOK,
+			Playlist:       newPlaylistData(pl),
+		})
+	}
+}
+
+func batchPlaylists(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+
+		var data []service.PlaylistInput
+
+		err := dec.Decode(&data)
 		if err != nil {
-			render.Render(w, r, responseInternalError(err))
+			render.Render(w, r, responseDecodeError(err))
 
 			return
 		}
 
-		if err = pl.Play(); err != nil {
-			render.Render(w, r, responseInternalError(err))
+		if len(data) < 1 {
+			render.Render(w, r, responseInvalidRequest(ErrNoPlaylistsProvided))
+
+			return
+		}
 
-			s.ChanErrorLog <- err
+		if err := service.ValidatePlaylists(data); err != nil {
+			render.Render(w, r, responseValidation(err))
 
 			return
 		}
 
-		render.Render(w, r, &messageResponse{
-			HTTPStatusCode: http.StatusOK,
-			MessageText:    "playlist is playing",
-			PlaylistId:     id,
+		ids, err := s.CreatePlaylists(r.Context(), data)
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidName) || errors.Is(err, service.ErrInvalidDuration) {
+				render.Render(w, r, responseUnprocessable(err))
+
+				return
+			}
+
+			if errors.Is(err, service.ErrDuplicateName) {
+				render.Render(w, r, responseConflict(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "batchPlaylists", err)
+
+			return
+		}
+
+		render.Render(w, r, &batchResponse{
+			HTTPStatusCode: http.StatusCreated,
+			Ids:            ids,
 		})
 	}
 }
 
-func pausePlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+func exportAll(s *service.Service) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		id, err := parseId(r, "id")
-		if err != nil {
-			render.Render(w, r, responseInvalidRequest(err))
+		if _, restricted := ownerFilter(r); restricted {
+			render.Render(w, r, responseForbidden(ErrForbidden))
 
 			return
 		}
 
-		pl, err := s.GetPlaylist(id)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+			"filename": "backup.json",
+		}))
+
+		bw, err := export.NewBackupWriter(w)
 		if err != nil {
-			render.Render(w, r, responseInternalError(err))
+			logError(s, r, "exportAll", err)
 
 			return
 		}
 
-		if err = pl.Pause(); err != nil {
-			render.Render(w, r, responseInternalError(err))
-
-			s.ChanErrorLog <- err
+		if err := s.ExportAll(bw.WritePlaylist); err != nil {
+			logError(s, r, "exportAll", err)
 
 			return
 		}
 
-		render.Render(w, r, &messageResponse{
-			HTTPStatusCode: http.StatusOK,
-			MessageText:    "playlist paused",
-			PlaylistId:     id,
-		})
+		if err := bw.Close(); err != nil {
+			logError(s, r, "exportAll", err)
+		}
 	}
 }
 
-func nextPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+func importAll(s *service.Service) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		id, err := parseId(r, "id")
-		if err != nil {
-			render.Render(w, r, responseInvalidRequest(err))
+		if _, restricted := ownerFilter(r); restricted {
+			render.Render(w, r, responseForbidden(ErrForbidden))
 
 			return
 		}
 
-		pl, err := s.GetPlaylist(id)
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+
+		var data export.Backup
+
+		err := dec.Decode(&data)
 		if err != nil {
-			render.Render(w, r, responseInternalError(err))
+			render.Render(w, r, responseDecodeError(err))
 
 			return
 		}
 
-		if err = pl.Next(); err != nil {
-			render.Render(w, r, responseInternalError(err))
+		ids, err := s.RestoreBackup(r.Context(), data)
+		if err != nil {
+			if errors.Is(err, export.ErrBackupVersion) {
+				render.Render(w, r, responseInvalidRequest(err))
+
+				return
+			}
+
+			if errors.Is(err, service.ErrInvalidName) || errors.Is(err, service.ErrInvalidDuration) {
+				render.Render(w, r, responseUnprocessable(err))
+
+				return
+			}
 
-			s.ChanErrorLog <- err
+			if errors.Is(err, service.ErrDuplicateName) {
+				render.Render(w, r, responseConflict(err))
+
+				return
+			}
+
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "importAll", err)
 
 			return
 		}
 
-		render.Render(w, r, &messageResponse{
-			HTTPStatusCode: http.StatusOK,
-			MessageText:    "playlist switched to next song",
-			PlaylistId:     id,
+		render.Render(w, r, &batchResponse{
+			HTTPStatusCode: http.StatusCreated,
+			Ids:            ids,
 		})
 	}
 }
 
-func prevPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+func songsPage(s *service.Service) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id, err := parseId(r, "id")
 		if err != nil {
@@ -445,30 +2460,38 @@ func prevPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
 			return
 		}
 
-		pl, err := s.GetPlaylist(id)
-		if err != nil {
-			render.Render(w, r, responseInternalError(err))
+		limit, err := parseQueryInt(r, "limit", defaultLimit)
+		if err != nil || limit < 1 {
+			render.Render(w, r, responseInvalidRequest(ErrInvalidLimit))
 
 			return
 		}
 
-		if err = pl.Prev(); err != nil {
-			render.Render(w, r, responseInternalError(err))
+		page, err := s.GetSongsPage(id, r.URL.Query().Get("cursor"), limit, r.URL.Query().Get("tag"))
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidCursor) {
+				render.Render(w, r, responseInvalidRequest(err))
+
+				return
+			}
 
-			s.ChanErrorLog <- err
+			render.Render(w, r, responseError(err))
+
+			logError(s, r, "songsPage", err)
 
 			return
 		}
 
-		render.Render(w, r, &messageResponse{
+		render.Render(w, r, &songsPageResponse{
 			HTTPStatusCode: http.StatusOK,
-			MessageText:    "playlist switched to prev song",
 			PlaylistId:     id,
+			Songs:          page.Songs,
+			NextCursor:     page.NextCursor,
 		})
 	}
 }
 
-func launchPlaylist(ctx context.Context, s *service.Service) func(http.ResponseWriter, *http.Request) {
+func topSongs(s *service.Service) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id, err := parseId(r, "id")
 		if err != nil {
@@ -477,30 +2500,29 @@ func launchPlaylist(ctx context.Context, s *service.Service) func(http.ResponseW
 			return
 		}
 
-		_, err = s.GetPlaylist(id)
-		if err != nil {
-			render.Render(w, r, responseInternalError(err))
+		limit, err := parseQueryInt(r, "limit", defaultTop)
+		if err != nil || limit < 1 {
+			render.Render(w, r, responseInvalidRequest(ErrInvalidLimit))
 
 			return
 		}
 
-		if err = s.LaunchPlaylist(ctx, id); err != nil {
-			render.Render(w, r, responseInternalError(err))
-
-			s.ChanErrorLog <- err
+		songs, err := s.TopSongs(id, limit)
+		if err != nil {
+			render.Render(w, r, responseError(err))
 
 			return
 		}
 
-		render.Render(w, r, &messageResponse{
+		render.Render(w, r, &songsResponse{
 			HTTPStatusCode: http.StatusOK,
-			MessageText:    "playlist is processing",
 			PlaylistId:     id,
+			Songs:          songs,
 		})
 	}
 }
 
-func stopPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+func historyPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id, err := parseId(r, "id")
 		if err != nil {
@@ -509,113 +2531,134 @@ func stopPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
 			return
 		}
 
-		pl, err := s.GetPlaylist(id)
-		if err != nil {
-			render.Render(w, r, responseInternalError(err))
+		limit, err := parseQueryInt(r, "limit", defaultRecent)
+		if err != nil || limit < 1 {
+			render.Render(w, r, responseInvalidRequest(ErrInvalidLimit))
 
 			return
 		}
 
-		if err = pl.Stop(); err != nil {
-			render.Render(w, r, responseInternalError(err))
-
-			s.ChanErrorLog <- err
+		entries, err := s.History(id, limit)
+		if err != nil {
+			render.Render(w, r, responseError(err))
 
 			return
 		}
 
-		render.Render(w, r, &messageResponse{
+		render.Render(w, r, &historyResponse{
 			HTTPStatusCode: http.StatusOK,
-			MessageText:    "playlist stopped",
 			PlaylistId:     id,
+			History:        entries,
 		})
 	}
 }
 
-func namePlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+func stopAll(s *service.Service) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		dec := json.NewDecoder(r.Body)
-		dec.DisallowUnknownFields()
+		result := s.StopAll(ownerFilter(r))
 
-		var data struct{ Name string }
+		for _, err := range result.Errors {
+			logError(s, r, "stopAll", err)
+		}
 
-		err := dec.Decode(&data)
-		if err != nil {
-			render.Render(w, r, responseInvalidRequest(ErrRequestBody))
+		render.Render(w, r, newBulkResponse(result))
+	}
+}
 
-			return
+func pauseAll(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		result := s.PauseAll(ownerFilter(r))
+
+		for _, err := range result.Errors {
+			logError(s, r, "pauseAll", err)
 		}
 
-		id, err := parseId(r, "id")
-		if err != nil {
-			render.Render(w, r, responseInvalidRequest(err))
+		render.Render(w, r, newBulkResponse(result))
+	}
+}
+
+func searchSongs(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, err := parseQueryInt(r, "limit", defaultLimit)
+		if err != nil || limit < 1 {
+			render.Render(w, r, responseInvalidRequest(ErrInvalidLimit))
 
 			return
 		}
 
-		_, err = s.GetPlaylist(id)
+		userId, restricted := ownerFilter(r)
+
+		matches, err := s.SearchSongs(r.Context(), r.URL.Query().Get("q"), limit, userId, restricted)
 		if err != nil {
-			render.Render(w, r, responseInternalError(err))
+			if errors.Is(err, service.ErrEmptyQuery) {
+				render.Render(w, r, responseInvalidRequest(err))
 
-			return
-		}
+				return
+			}
 
-		if err = s.EditPlaylist(id, data.Name); err != nil {
-			render.Render(w, r, responseInternalError(err))
+			render.Render(w, r, responseError(err))
 
-			s.ChanErrorLog <- err
+			logError(s, r, "searchSongs", err)
 
 			return
 		}
 
-		render.Render(w, r, &messageResponse{
+		render.Render(w, r, &matchesResponse{
 			HTTPStatusCode: http.StatusOK,
-			MessageText:    "playlist renamed",
-			PlaylistId:     id,
+			Songs:          matches,
 		})
 	}
 }
 
-func timePlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+func songPlaylists(s *service.Service) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		dec := json.NewDecoder(r.Body)
-		dec.DisallowUnknownFields()
-
-		var data struct{ Time uint }
-
-		err := dec.Decode(&data)
-		if err != nil {
-			render.Render(w, r, responseInvalidRequest(ErrRequestBody))
+		name, err := url.PathUnescape(chi.URLParam(r, "name"))
+		if err != nil || name == "" {
+			render.Render(w, r, responseInvalidRequest(service.ErrInvalidName))
 
 			return
 		}
 
-		id, err := parseId(r, "id")
-		if err != nil {
-			render.Render(w, r, responseInvalidRequest(err))
+		found := s.PlaylistsContainingSong(name)
 
-			return
+		if userId, restricted := ownerFilter(r); restricted {
+			found = service.OwnedBy(found, userId)
 		}
 
-		pl, err := s.GetPlaylist(id)
-		if err != nil {
-			render.Render(w, r, responseInternalError(err))
+		render.Render(w, r, &refsResponse{
+			HTTPStatusCode: http.StatusOK,
+			Playlists:      service.Refs(found),
+		})
+	}
+}
 
-			return
-		}
+func favoriteSongs(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userId, restricted := ownerFilter(r)
 
-		if err = pl.SetTime(data.Time); err != nil {
-			render.Render(w, r, responseInternalError(err))
+		render.Render(w, r, &matchesResponse{
+			HTTPStatusCode: http.StatusOK,
+			Songs:          s.Favorites(userId, restricted),
+		})
+	}
+}
+
+func stats(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userId, restricted := ownerFilter(r)
+
+		st, err := s.Stats(r.Context(), userId, restricted)
+		if err != nil {
+			render.Render(w, r, responseError(err))
 
-			s.ChanErrorLog <- err
+			logError(s, r, "stats", err)
 
 			return
 		}
 
-		render.Render(w, r, &messageResponse{
+		render.Render(w, r, &statsResponse{
 			HTTPStatusCode: http.StatusOK,
-			MessageText:    "playlist time set",
-			PlaylistId:     id,
+			Stats:          st,
 		})
 	}
 }
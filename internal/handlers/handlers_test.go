@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gocloudcamp_test/internal/database"
+	"gocloudcamp_test/internal/database/dbtest"
+	"gocloudcamp_test/internal/playlist"
+	"gocloudcamp_test/internal/service"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+
+	os.Exit(m.Run())
+}
+
+type testServer struct {
+	s       *service.Service
+	store   *dbtest.Store
+	handler http.Handler
+}
+
+func newTestServer(t *testing.T, config service.Config) *testServer {
+	t.Helper()
+
+	db, store := dbtest.Open(t)
+
+	if config.Logger == nil {
+		config.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	s := service.New(db, config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	return &testServer{s: s, store: store, handler: New(ctx, s)}
+}
+
+func (ts *testServer) playlist(t *testing.T, name string, durations ...uint) *playlist.Playlist {
+	t.Helper()
+
+	dbsns := make([]database.Song, len(durations))
+
+	for i, duration := range durations {
+		dbsns[i] = database.Song{Name: "song", Duration: database.Duration(duration)}
+	}
+
+	dbpl := &database.Playlist{Name: name}
+
+	if err := ts.s.CreatePlaylistWithSongs(context.Background(), dbpl, dbsns); err != nil {
+		t.Fatalf("create playlist: %v", err)
+	}
+
+	pl, err := ts.s.GetPlaylist(dbpl.Id)
+	if err != nil {
+		t.Fatalf("get playlist: %v", err)
+	}
+
+	return pl
+}
+
+func (ts *testServer) launch(t *testing.T, pl *playlist.Playlist) {
+	t.Helper()
+
+	rec := ts.do(t, http.MethodPost, fmt.Sprintf("/v1/playlist/%d/launch", pl.Id), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("launch status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	t.Cleanup(func() { ts.s.StopLaunch(pl.Id) })
+
+	for !pl.IsProcessing() {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func (ts *testServer) do(t *testing.T, method string, target string, body string, header ...string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+
+	rec := httptest.NewRecorder()
+
+	ts.handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func decodeError(t *testing.T, rec *httptest.ResponseRecorder) errorResponse {
+	t.Helper()
+
+	var resp errorResponse
+
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode error response %q: %v", rec.Body.String(), err)
+	}
+
+	return resp
+}
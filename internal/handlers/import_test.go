@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gocloudcamp_test/internal/service"
+)
+
+func TestImportPlaylistM3U(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		status int
+		code   string
+		line   string
+	}{
+		{"valid", "#EXTM3U\n#EXTINF:60,Intro\nintro.mp3\noutro.mp3\n", http.StatusCreated, "", ""},
+		{"invalid extinf", "#EXTM3U\nintro.mp3\n#EXTINF:abc,Outro\noutro.mp3\n", http.StatusBadRequest, "invalid_m3u", "line 3"},
+		{"missing location", "#EXTM3U\n#EXTINF:60,Intro\n", http.StatusBadRequest, "invalid_m3u", "line 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(t, service.Config{})
+
+			var body bytes.Buffer
+
+			mw := multipart.NewWriter(&body)
+
+			fw, err := mw.CreateFormFile("file", "imported.m3u")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := fw.Write([]byte(tt.file)); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := mw.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/playlist/import", &body)
+			req.Header.Set("Content-Type", mw.FormDataContentType())
+
+			rec := httptest.NewRecorder()
+
+			ts.handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+
+			if tt.code == "" {
+				return
+			}
+
+			resp := decodeError(t, rec)
+
+			if resp.Code != tt.code {
+				t.Fatalf("code %q, want %q", resp.Code, tt.code)
+			}
+
+			if !strings.HasPrefix(resp.ErrorText, tt.line+":") {
+				t.Fatalf("error %q does not name %s", resp.ErrorText, tt.line)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"gocloudcamp_test/internal/playlist"
+)
+
+const (
+	jsonAPITypePlaylists = "playlists"
+	jsonAPITypeSongs     = "songs"
+)
+
+type jsonAPIIdentifier struct {
+	Type string `json:"type"`
+	Id   string `json:"id"`
+}
+
+type jsonAPIRelationship struct {
+	Data any `json:"data"`
+}
+
+type jsonAPIResource struct {
+	jsonAPIIdentifier
+	Attributes    any                            `json:"attributes,omitempty"`
+	Relationships map[string]jsonAPIRelationship `json:"relationships,omitempty"`
+}
+
+type jsonAPISource struct {
+	Pointer string `json:"pointer"`
+}
+
+type jsonAPIError struct {
+	Status string         `json:"status"`
+	Code   string         `json:"code,omitempty"`
+	Title  string         `json:"title,omitempty"`
+	Detail string         `json:"detail,omitempty"`
+	Source *jsonAPISource `json:"source,omitempty"`
+}
+
+type jsonAPIDocument struct {
+	Data     any               `json:"data,omitempty"`
+	Included []jsonAPIResource `json:"included,omitempty"`
+	Errors   []jsonAPIError    `json:"errors,omitempty"`
+	Meta     any               `json:"meta,omitempty"`
+}
+
+func newJSONAPIDocument(v any) jsonAPIDocument {
+	switch v := v.(type) {
+	case *playlistResponse:
+		resource, included := playlistResource(v.Playlist)
+
+		return jsonAPIDocument{Data: resource, Included: included}
+	case *allResponse:
+		data, included := make([]jsonAPIResource, 0, len(v.Playlists)), []jsonAPIResource{}
+
+		for _, pl := range v.Playlists {
+			resource, songs := playlistResource(pl)
+
+			data = append(data, resource)
+			included = append(included, songs...)
+		}
+
+		return jsonAPIDocument{Data: data, Included: included, Meta: map[string]int{"total": v.Total}}
+	case *songsResponse:
+		doc := jsonAPIDocument{Data: songResources(v.PlaylistId, v.Songs)}
+
+		if v.Skipped > 0 {
+			doc.Meta = map[string]int{"skipped": v.Skipped}
+		}
+
+		return doc
+	case *songResponse:
+		return jsonAPIDocument{Data: songResources(v.PlaylistId, []playlist.Song{v.Song})[0]}
+	case *songsPageResponse:
+		doc := jsonAPIDocument{Data: songResources(v.PlaylistId, v.Songs)}
+
+		if v.NextCursor != "" {
+			doc.Meta = map[string]string{"next_cursor": v.NextCursor}
+		}
+
+		return doc
+	case *errorResponse:
+		return jsonAPIDocument{Errors: jsonAPIErrors(v)}
+	}
+
+	return jsonAPIDocument{Meta: v}
+}
+
+func playlistResource(data playlistData) (jsonAPIResource, []jsonAPIResource) {
+	attributes := data
+	attributes.Songs = nil
+
+	resource := jsonAPIResource{
+		jsonAPIIdentifier: jsonAPIIdentifier{Type: jsonAPITypePlaylists, Id: formatId(data.id)},
+		Attributes:        attributes,
+	}
+
+	if data.Songs == nil {
+		return resource, nil
+	}
+
+	included := songResources(data.id, data.Songs)
+	identifiers := make([]jsonAPIIdentifier, 0, len(included))
+
+	for _, sn := range included {
+		identifiers = append(identifiers, sn.jsonAPIIdentifier)
+	}
+
+	resource.Relationships = map[string]jsonAPIRelationship{
+		jsonAPITypeSongs: {Data: identifiers},
+	}
+
+	return resource, included
+}
+
+func songResources(plId uint, sns []playlist.Song) []jsonAPIResource {
+	resources := make([]jsonAPIResource, 0, len(sns))
+
+	for _, sn := range sns {
+		resource := jsonAPIResource{
+			jsonAPIIdentifier: jsonAPIIdentifier{Type: jsonAPITypeSongs, Id: formatId(sn.Id)},
+			Attributes:        songAttributes(sn),
+		}
+
+		if plId != 0 {
+			resource.Relationships = map[string]jsonAPIRelationship{
+				"playlist": {Data: jsonAPIIdentifier{Type: jsonAPITypePlaylists, Id: formatId(plId)}},
+			}
+		}
+
+		resources = append(resources, resource)
+	}
+
+	return resources
+}
+
+func songAttributes(sn playlist.Song) map[string]any {
+	var attributes map[string]any
+
+	if raw, err := json.Marshal(sn); err == nil {
+		json.Unmarshal(raw, &attributes)
+	}
+
+	delete(attributes, "Id")
+
+	return attributes
+}
+
+func jsonAPIErrors(er *errorResponse) []jsonAPIError {
+	status := strconv.Itoa(er.HTTPStatusCode)
+
+	if len(er.Errors) == 0 {
+		return []jsonAPIError{{Status: status, Code: er.Code, Title: er.MessageText, Detail: er.ErrorText}}
+	}
+
+	errs := make([]jsonAPIError, 0, len(er.Errors))
+
+	for _, fe := range er.Errors {
+		errs = append(errs, jsonAPIError{
+			Status: status,
+			Code:   fe.Code,
+			Title:  er.MessageText,
+			Detail: fe.MessageText,
+			Source: &jsonAPISource{Pointer: jsonPointer(fe.Field)},
+		})
+	}
+
+	return errs
+}
+
+func jsonPointer(field string) string {
+	return "/" + strings.TrimPrefix(strings.NewReplacer("[", "/", "]", "", ".", "/").Replace(field), "/")
+}
+
+func formatId(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
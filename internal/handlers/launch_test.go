@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"gocloudcamp_test/internal/playlist"
+	"gocloudcamp_test/internal/service"
+)
+
+func TestDoubleLaunch(t *testing.T) {
+	ts := newTestServer(t, service.Config{})
+	pl := ts.playlist(t, "launch", 60)
+
+	ts.launch(t, pl)
+
+	rec := ts.do(t, http.MethodPost, fmt.Sprintf("/v1/playlist/%d/launch", pl.Id), "")
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status %d, want %d: %s", rec.Code, http.StatusConflict, rec.Body.String())
+	}
+
+	if code := decodeError(t, rec).Code; code != "already_launched" {
+		t.Fatalf("code %q, want %q", code, "already_launched")
+	}
+
+	if n := ts.s.Workers().Launched; n != 1 {
+		t.Fatalf("%d active workers, want 1", n)
+	}
+}
+
+func TestConcurrentLaunch(t *testing.T) {
+	ts := newTestServer(t, service.Config{})
+	pl := ts.playlist(t, "launch", 60)
+
+	t.Cleanup(func() { ts.s.StopLaunch(pl.Id) })
+
+	const n = 16
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		statuses = make(map[int]int)
+	)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			rec := ts.do(t, http.MethodPost, fmt.Sprintf("/v1/playlist/%d/launch", pl.Id), "")
+
+			mu.Lock()
+			statuses[rec.Code]++
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	if statuses[http.StatusOK] != 1 || statuses[http.StatusConflict] != n-1 {
+		t.Fatalf("statuses %v, want one %d and %d %d", statuses, http.StatusOK, n-1, http.StatusConflict)
+	}
+
+	if n := ts.s.Workers().Launched; n != 1 {
+		t.Fatalf("%d active workers, want 1", n)
+	}
+}
+
+func TestLaunchLimit(t *testing.T) {
+	const limit = 2
+
+	ts := newTestServer(t, service.Config{MaxLaunches: limit})
+
+	pls := []*playlist.Playlist{
+		ts.playlist(t, "first", 60),
+		ts.playlist(t, "second", 60),
+		ts.playlist(t, "third", 60),
+	}
+
+	for _, pl := range pls[:limit] {
+		ts.launch(t, pl)
+	}
+
+	rec := ts.do(t, http.MethodPost, fmt.Sprintf("/v1/playlist/%d/launch", pls[limit].Id), "")
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status %d, want %d: %s", rec.Code, http.StatusTooManyRequests, rec.Body.String())
+	}
+
+	if code := decodeError(t, rec).Code; code != "launch_limit" {
+		t.Fatalf("code %q, want %q", code, "launch_limit")
+	}
+
+	rec = ts.do(t, http.MethodGet, "/v1/stats", "")
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("stats status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var st service.Stats
+
+	if err := json.Unmarshal(rec.Body.Bytes(), &st); err != nil {
+		t.Fatal(err)
+	}
+
+	if st.Launched != limit || st.MaxLaunches != limit {
+		t.Fatalf("stats launched %d of %d, want %d of %d", st.Launched, st.MaxLaunches, limit, limit)
+	}
+
+	if rec := ts.do(t, http.MethodPost, fmt.Sprintf("/v1/playlist/%d/stop", pls[0].Id), ""); rec.Code != http.StatusOK {
+		t.Fatalf("stop status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	ts.launch(t, pls[limit])
+
+	if n := ts.s.Workers().Launched; n != limit {
+		t.Fatalf("%d active workers, want %d", n, limit)
+	}
+}
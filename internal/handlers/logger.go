@@ -1,14 +1,17 @@
 package handlers
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 
+	"gocloudcamp_test/internal/service"
+
+	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
 )
 
-func requestLogger() func(next http.Handler) http.Handler {
+func requestLogger(logger *slog.Logger) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
 			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
@@ -16,14 +19,28 @@ func requestLogger() func(next http.Handler) http.Handler {
 			t := time.Now()
 
 			defer func() {
-				log.Printf(
-					"http | request | status %d | method %s | uri %s | address %s | latency %dns | bytes %d",
-					ww.Status(),
-					r.Method,
-					r.RequestURI,
-					r.RemoteAddr,
-					time.Since(t).Nanoseconds(),
-					ww.BytesWritten(),
+				level := slog.LevelInfo
+
+				switch {
+				case ww.Status() >= http.StatusInternalServerError:
+					level = slog.LevelError
+				case ww.Status() >= http.StatusBadRequest:
+					level = slog.LevelWarn
+				}
+
+				logger.LogAttrs(
+					r.Context(),
+					level,
+					"http request",
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+					slog.String("endpoint", routePattern(r)),
+					slog.String("playlist_id", chi.URLParam(r, "id")),
+					slog.Int("status", ww.Status()),
+					slog.String("method", r.Method),
+					slog.String("uri", redactedURI(r)),
+					slog.String("address", r.RemoteAddr),
+					slog.Duration("latency", time.Since(t)),
+					slog.Int("bytes", ww.BytesWritten()),
 				)
 			}()
 
@@ -33,3 +50,23 @@ func requestLogger() func(next http.Handler) http.Handler {
 		return http.HandlerFunc(fn)
 	}
 }
+
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern
+		}
+	}
+
+	return r.URL.Path
+}
+
+func logError(s *service.Service, r *http.Request, op string, err error) {
+	s.LogError(
+		op,
+		err,
+		slog.String("request_id", middleware.GetReqID(r.Context())),
+		slog.String("endpoint", routePattern(r)),
+		slog.String("playlist_id", chi.URLParam(r, "id")),
+	)
+}
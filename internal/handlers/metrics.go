@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"gocloudcamp_test/internal/metrics"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+)
+
+func requestMetrics() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			t := time.Now()
+
+			defer func() {
+				endpoint := "unknown"
+
+				if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+					endpoint = rctx.RoutePattern()
+				}
+
+				metrics.Requests.WithLabelValues(endpoint, r.Method, strconv.Itoa(ww.Status())).Inc()
+				metrics.Latency.WithLabelValues(endpoint, r.Method).Observe(time.Since(t).Seconds())
+			}()
+
+			next.ServeHTTP(ww, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gocloudcamp_test/internal/service"
+)
+
+func TestPlaylistName(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		status int
+	}{
+		{"empty", "", http.StatusUnprocessableEntity},
+		{"whitespace", " \t ", http.StatusUnprocessableEntity},
+		{"multibyte at limit", strings.Repeat("я", service.MaxNameLength), 0},
+		{"multibyte over limit", strings.Repeat("я", service.MaxNameLength+1), http.StatusUnprocessableEntity},
+		{"emoji at limit", strings.Repeat("🎵", service.MaxNameLength), 0},
+	}
+
+	for _, tt := range tests {
+		for _, rename := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s rename %t", tt.name, rename), func(t *testing.T) {
+				ts := newTestServer(t, service.Config{})
+
+				name, err := json.Marshal(tt.in)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				method, target := http.MethodPost, "/v1/playlist"
+				if rename {
+					method, target = http.MethodPatch, fmt.Sprintf("/v1/playlist/%d/name", ts.playlist(t, "name").Id)
+				}
+
+				rec := ts.do(t, method, target, fmt.Sprintf(`{"Name":%s}`, name))
+
+				if tt.status == 0 {
+					if rec.Code >= http.StatusMultipleChoices {
+						t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+					}
+
+					return
+				}
+
+				if rec.Code != tt.status {
+					t.Fatalf("status %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+				}
+
+				if code := decodeError(t, rec).Code; code != "invalid_name" {
+					t.Fatalf("code %q, want %q", code, "invalid_name")
+				}
+			})
+		}
+	}
+}
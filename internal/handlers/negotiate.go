@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/render"
+)
+
+const (
+	contentTypeJSONAPI render.ContentType = iota + 100
+	contentTypeHAL
+)
+
+const (
+	mediaTypeJSONAPI = "application/vnd.api+json"
+	mediaTypeHAL     = "application/hal+json"
+)
+
+func init() {
+	render.Respond = respond
+}
+
+func acceptedContentType(accept string) render.ContentType {
+	for _, field := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(field))
+		if err != nil || params["q"] == "0" {
+			continue
+		}
+
+		switch mediaType {
+		case "application/xml", "text/xml":
+			return render.ContentTypeXML
+		case "application/json":
+			return render.ContentTypeJSON
+		case mediaTypeJSONAPI:
+			return contentTypeJSONAPI
+		case mediaTypeHAL:
+			return contentTypeHAL
+		}
+	}
+
+	return render.ContentTypeJSON
+}
+
+func requestNegotiate() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept")
+
+			ctx := context.WithValue(r.Context(), render.ContentTypeCtxKey, acceptedContentType(r.Header.Get("Accept")))
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+func respond(w http.ResponseWriter, r *http.Request, v any) {
+	switch render.GetAcceptedContentType(r) {
+	case contentTypeJSONAPI:
+		writeJSON(w, r, mediaTypeJSONAPI, newJSONAPIDocument(v))
+	case contentTypeHAL:
+		addHALLinks(r, v)
+
+		writeJSON(w, r, mediaTypeHAL, v)
+	default:
+		render.DefaultResponder(w, r, v)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, mediaType string, v any) {
+	buf := &bytes.Buffer{}
+
+	if err := json.NewEncoder(buf).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", mediaType)
+
+	if status, ok := r.Context().Value(render.StatusCtxKey).(int); ok {
+		w.WriteHeader(status)
+	}
+
+	w.Write(buf.Bytes())
+}
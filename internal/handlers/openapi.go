@@ -0,0 +1,434 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gocloudcamp_test/internal/build"
+	"gocloudcamp_test/internal/database"
+	"gocloudcamp_test/internal/export"
+	"gocloudcamp_test/internal/playlist"
+	"gocloudcamp_test/internal/service"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+)
+
+type apiOperation struct {
+	Summary      string
+	Request      any
+	RequestType  string
+	Response     any
+	ResponseType string
+	Status       int
+	Query        []string
+}
+
+type (
+	nameRequest     struct{ Name string }
+	positionRequest struct{ Position int }
+	targetRequest   struct{ Target uint }
+	indexRequest    struct{ Index int }
+	swapRequest     struct{ A, B uint }
+	queueRequest    struct{ Song uint }
+	timeRequest     struct{ Time uint }
+	shuffleRequest  struct{ Shuffle bool }
+	repeatRequest   struct{ Mode playlist.Repeat }
+	speedRequest    struct{ Speed float64 }
+	volumeRequest   struct{ Volume int }
+	sleepRequest    struct{ Minutes uint }
+	scheduleRequest struct{ At time.Time }
+	webhookRequest  struct{ Url string }
+	createRequest   struct {
+		Name  string
+		Songs []database.Song
+	}
+	importRequest struct {
+		File []byte `json:"file"`
+		Name string `json:"name"`
+	}
+)
+
+var apiOperations = map[string]apiOperation{
+	"GET /v1/shared/{token}":                       {Summary: "Get shared playlist", Response: playlistResponse{}},
+	"POST /v1/playlists/batch":                     {Summary: "Create playlists in batch", Request: []service.PlaylistInput{}, Response: batchResponse{}, Status: http.StatusCreated},
+	"POST /v1/playlists/stop-all":                  {Summary: "Stop all launched playlists", Response: bulkResponse{}},
+	"POST /v1/playlists/pause-all":                 {Summary: "Pause all launched playlists", Response: bulkResponse{}},
+	"GET /v1/songs/search":                         {Summary: "Search songs across playlists", Response: matchesResponse{}, Query: []string{"q", "limit"}},
+	"GET /v1/songs/{name}/playlists":               {Summary: "List playlists containing song", Response: refsResponse{}},
+	"GET /v1/songs/favorites":                      {Summary: "List favorite songs", Response: matchesResponse{}},
+	"GET /v1/stats":                                {Summary: "Get statistics", Response: statsResponse{}},
+	"GET /v1/debug/workers":                        {Summary: "Get playback workers", Response: workersResponse{}},
+	"GET /v1/export":                               {Summary: "Export backup of all playlists", Response: export.Backup{}},
+	"POST /v1/import":                              {Summary: "Restore playlists from backup", Request: export.Backup{}, Response: batchResponse{}, Status: http.StatusCreated},
+	"GET /v1/playlist":                             {Summary: "List playlists", Response: allResponse{}, Query: []string{"limit", "offset", "name", "status", "sort", "fields", "expand"}},
+	"POST /v1/playlist":                            {Summary: "Create playlist", Request: createRequest{}, Response: playlistResponse{}, Status: http.StatusCreated},
+	"POST /v1/playlist/import":                     {Summary: "Import playlist from M3U", Request: importRequest{}, RequestType: "multipart/form-data", Response: playlistResponse{}, Status: http.StatusCreated},
+	"GET /v1/playlist/{id}":                        {Summary: "Get playlist", Response: playlistResponse{}, Query: []string{"fields", "expand"}},
+	"GET /v1/playlist/{id}/export":                 {Summary: "Export playlist", ResponseType: "audio/x-mpegurl", Query: []string{"format"}},
+	"GET /v1/playlist/{id}/ws":                     {Summary: "Subscribe to playback over WebSocket", Status: http.StatusSwitchingProtocols},
+	"GET /v1/playlist/{id}/events":                 {Summary: "Subscribe to playback over Server-Sent Events", ResponseType: "text/event-stream"},
+	"PATCH /v1/playlist/{id}/name":                 {Summary: "Rename playlist", Request: nameRequest{}, Response: messageResponse{}},
+	"GET /v1/playlist/{id}/time":                   {Summary: "Get elapsed time", Response: timeResponse{}},
+	"PATCH /v1/playlist/{id}/time":                 {Summary: "Set elapsed time", Request: timeRequest{}, Response: messageResponse{}},
+	"GET /v1/playlist/{id}/remaining":              {Summary: "Get remaining time", Response: remainingResponse{}},
+	"GET /v1/playlist/{id}/top":                    {Summary: "Get most played songs", Response: songsResponse{}, Query: []string{"limit"}},
+	"GET /v1/playlist/{id}/history":                {Summary: "Get playback history", Response: historyResponse{}, Query: []string{"limit"}},
+	"PATCH /v1/playlist/{id}/shuffle":              {Summary: "Toggle shuffle", Request: shuffleRequest{}, Response: messageResponse{}},
+	"PATCH /v1/playlist/{id}/repeat":               {Summary: "Set repeat mode", Request: repeatRequest{}, Response: messageResponse{}},
+	"POST /v1/playlist/{id}/restore":               {Summary: "Restore deleted playlist", Response: messageResponse{}},
+	"DELETE /v1/playlist/{id}/purge":               {Summary: "Purge playlist permanently", Response: messageResponse{}},
+	"PATCH /v1/playlist/{id}/volume":               {Summary: "Set volume", Request: volumeRequest{}, Response: messageResponse{}},
+	"PATCH /v1/playlist/{id}/speed":                {Summary: "Set playback speed", Request: speedRequest{}, Response: messageResponse{}},
+	"DELETE /v1/playlist/{id}":                     {Summary: "Delete playlist", Response: messageResponse{}},
+	"POST /v1/playlist/{id}/clone":                 {Summary: "Clone playlist", Request: nameRequest{}, Response: messageResponse{}, Status: http.StatusCreated},
+	"POST /v1/playlist/{id}/share":                 {Summary: "Share playlist", Response: shareResponse{}, Status: http.StatusCreated},
+	"DELETE /v1/playlist/{id}/share":               {Summary: "Revoke playlist share", Response: messageResponse{}},
+	"POST /v1/playlist/{id}/launch":                {Summary: "Launch playlist", Response: messageResponse{}},
+	"POST /v1/playlist/{id}/stop":                  {Summary: "Stop playlist", Response: messageResponse{}},
+	"POST /v1/playlist/{id}/play":                  {Summary: "Resume playback", Response: messageResponse{}},
+	"POST /v1/playlist/{id}/pause":                 {Summary: "Pause playback", Response: messageResponse{}},
+	"POST /v1/playlist/{id}/next":                  {Summary: "Switch to next song", Response: messageResponse{}},
+	"POST /v1/playlist/{id}/prev":                  {Summary: "Switch to previous song", Response: messageResponse{}},
+	"POST /v1/playlist/{id}/seek":                  {Summary: "Jump to song by index", Request: indexRequest{}, Response: messageResponse{}},
+	"POST /v1/playlist/{id}/queue":                 {Summary: "Queue song to play next", Request: queueRequest{}, Response: messageResponse{}},
+	"POST /v1/playlist/{id}/restart":               {Summary: "Restart playlist from first song", Response: songResponse{}},
+	"POST /v1/playlist/{id}/sleep":                 {Summary: "Set sleep timer", Request: sleepRequest{}, Response: sleepResponse{}},
+	"POST /v1/playlist/{id}/schedule":              {Summary: "Schedule launch", Request: scheduleRequest{}, Response: scheduleResponse{}},
+	"DELETE /v1/playlist/{id}/schedule":            {Summary: "Cancel scheduled launch", Response: messageResponse{}},
+	"PATCH /v1/playlist/{id}/webhook":              {Summary: "Set webhook", Request: webhookRequest{}, Response: messageResponse{}},
+	"POST /v1/playlist/{id}/song":                  {Summary: "Add songs", Request: []database.Song{}, Response: songsResponse{}, Status: http.StatusCreated, Query: []string{"position", "dedup"}},
+	"GET /v1/playlist/{id}/songs":                  {Summary: "List songs page", Response: songsPageResponse{}, Query: []string{"cursor", "limit", "tag"}},
+	"PUT /v1/playlist/{id}/songs":                  {Summary: "Replace songs", Request: []database.Song{}, Response: countResponse{}},
+	"PATCH /v1/playlist/{id}/song/{sid}":           {Summary: "Edit song", Request: database.Song{}, Response: messageResponse{}},
+	"POST /v1/playlist/{id}/swap":                  {Summary: "Swap two songs", Request: swapRequest{}, Response: messageResponse{}},
+	"POST /v1/playlist/{id}/song/{sid}/move":       {Summary: "Move song", Request: positionRequest{}, Response: messageResponse{}},
+	"POST /v1/playlist/{id}/song/{sid}/transfer":   {Summary: "Transfer song to another playlist", Request: targetRequest{}, Response: messageResponse{}},
+	"POST /v1/playlist/{id}/song/{sid}/play":       {Summary: "Play song", Response: messageResponse{}},
+	"POST /v1/playlist/{id}/song/{sid}/favorite":   {Summary: "Add song to favorites", Response: messageResponse{}},
+	"DELETE /v1/playlist/{id}/song/{sid}/favorite": {Summary: "Remove song from favorites", Response: messageResponse{}},
+	"DELETE /v1/playlist/{id}/song/{sid}":          {Summary: "Remove song", Response: messageResponse{}},
+}
+
+var apiQuery = map[string]string{
+	"limit":    "integer",
+	"offset":   "integer",
+	"position": "integer",
+	"name":     "string",
+	"status":   "string",
+	"sort":     "string",
+	"format":   "string",
+	"cursor":   "string",
+	"fields":   "string",
+	"expand":   "string",
+	"tag":      "string",
+	"q":        "string",
+	"dedup":    "boolean",
+}
+
+var routeParam = regexp.MustCompile(`\{(\w+)\}`)
+
+type apiSpec struct {
+	once sync.Once
+	spec map[string]any
+}
+
+func openapi(router chi.Routes) func(http.ResponseWriter, *http.Request) {
+	spec := &apiSpec{}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		spec.once.Do(func() { spec.spec = newApiSpec(router) })
+
+		render.JSON(w, r, spec.spec)
+	}
+}
+
+func newApiSpec(router chi.Routes) map[string]any {
+	schemas := map[string]any{}
+	paths := map[string]map[string]any{}
+
+	schemaOf(reflect.TypeOf(errorResponse{}), schemas)
+
+	chi.Walk(router, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		route = strings.TrimSuffix(route, "/")
+		if !strings.HasPrefix(route, "/v1") {
+			return nil
+		}
+
+		op := apiOperations[method+" "+route]
+
+		if paths[route] == nil {
+			paths[route] = map[string]any{}
+		}
+
+		paths[route][strings.ToLower(method)] = newApiOperation(method, route, op, schemas)
+
+		return nil
+	})
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "Player Service",
+			"version": build.Get().Version,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"bearer": map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+	}
+}
+
+func newApiOperation(method, route string, op apiOperation, schemas map[string]any) map[string]any {
+	var params []map[string]any
+
+	for _, match := range routeParam.FindAllStringSubmatch(route, -1) {
+		kind := "integer"
+		if match[1] == "token" || match[1] == "name" {
+			kind = "string"
+		}
+
+		params = append(params, map[string]any{
+			"name":     match[1],
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]any{"type": kind},
+		})
+	}
+
+	for _, name := range op.Query {
+		params = append(params, map[string]any{
+			"name":   name,
+			"in":     "query",
+			"schema": map[string]any{"type": apiQuery[name]},
+		})
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	success := map[string]any{"description": http.StatusText(status)}
+
+	switch {
+	case op.Response != nil:
+		success["content"] = map[string]any{
+			"application/json": map[string]any{"schema": schemaOf(reflect.TypeOf(op.Response), schemas)},
+		}
+	case op.ResponseType != "":
+		success["content"] = map[string]any{op.ResponseType: map[string]any{}}
+	}
+
+	operation := map[string]any{
+		"summary":     op.Summary,
+		"operationId": operationId(method, route),
+		"responses": map[string]any{
+			fmt.Sprint(status): success,
+			"default": map[string]any{
+				"description": "Error",
+				"content": map[string]any{
+					"application/json": map[string]any{"schema": schemaRef("errorResponse")},
+				},
+			},
+		},
+	}
+
+	if op.Summary == "" {
+		operation["summary"] = method + " " + route
+	}
+
+	if len(params) > 0 {
+		operation["parameters"] = params
+	}
+
+	if op.Request != nil {
+		contentType := op.RequestType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+
+		operation["requestBody"] = map[string]any{
+			"required": true,
+			"content": map[string]any{
+				contentType: map[string]any{"schema": schemaOf(reflect.TypeOf(op.Request), schemas)},
+			},
+		}
+	}
+
+	if !strings.HasPrefix(route, "/v1/shared") {
+		operation["security"] = []map[string]any{{"bearer": []string{}}}
+	}
+
+	return operation
+}
+
+func operationId(method, route string) string {
+	var parts []string
+
+	for _, part := range strings.Split(strings.TrimPrefix(route, "/v1/"), "/") {
+		part = strings.Trim(part, "{}")
+		part = strings.ReplaceAll(part, "-", "")
+
+		if part != "" {
+			parts = append(parts, strings.ToUpper(part[:1])+part[1:])
+		}
+	}
+
+	return strings.ToLower(method) + strings.Join(parts, "")
+}
+
+func schemaRef(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+func schemaName(t reflect.Type) string {
+	pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+	if pkg == "handlers" {
+		return t.Name()
+	}
+
+	return pkg + "." + t.Name()
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(database.Duration(0))
+	songType     = reflect.TypeOf(playlist.Song{})
+)
+
+func schemaOf(t reflect.Type, schemas map[string]any) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case durationType:
+		return map[string]any{
+			"oneOf": []map[string]any{
+				{"type": "integer", "minimum": 0},
+				{"type": "string", "example": "3:25"},
+			},
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "binary"}
+		}
+
+		return map[string]any{"type": "array", "items": schemaOf(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaOf(t.Elem(), schemas)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return structSchema(t, schemas)
+		}
+
+		name := schemaName(t)
+
+		if _, ok := schemas[name]; !ok {
+			schemas[name] = map[string]any{}
+			schemas[name] = structSchema(t, schemas)
+		}
+
+		return schemaRef(name)
+	}
+
+	return map[string]any{}
+}
+
+func structSchema(t reflect.Type, schemas map[string]any) map[string]any {
+	props := map[string]any{}
+
+	collectFields(t, props, schemas)
+
+	if t == songType {
+		props["duration_human"] = map[string]any{"type": "string"}
+	}
+
+	return map[string]any{
+		"type":       "object",
+		"properties": props,
+	}
+}
+
+func collectFields(t reflect.Type, props map[string]any, schemas map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+
+			if embedded.Kind() == reflect.Struct {
+				collectFields(embedded, props, schemas)
+
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		props[name] = schemaOf(field.Type, schemas)
+	}
+}
+
+const docsPage = `<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Player Service</title>
+	<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+	<div id="swagger-ui"></div>
+	<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+	<script>
+		window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });
+	</script>
+</body>
+</html>
+`
+
+func docs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	w.Write([]byte(docsPage))
+}
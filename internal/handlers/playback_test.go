@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"gocloudcamp_test/internal/service"
+)
+
+func TestEmptyPlaylistControls(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodPost, "/launch", ""},
+		{http.MethodPost, "/play", ""},
+		{http.MethodPost, "/next", ""},
+		{http.MethodPost, "/prev", ""},
+		{http.MethodPost, "/restart", ""},
+		{http.MethodPatch, "/time", `{"Time":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			ts := newTestServer(t, service.Config{})
+			pl := ts.playlist(t, "empty")
+
+			rec := ts.do(t, tt.method, fmt.Sprintf("/v1/playlist/%d%s", pl.Id, tt.path), tt.body)
+
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("status %d, want %d: %s", rec.Code, http.StatusUnprocessableEntity, rec.Body.String())
+			}
+
+			if code := decodeError(t, rec).Code; code != "no_songs" {
+				t.Fatalf("code %q, want %q", code, "no_songs")
+			}
+
+			if pl.IsProcessing() {
+				t.Fatal("empty playlist started processing")
+			}
+		})
+	}
+}
+
+func TestPlaybackStateErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		launch bool
+		setup  []string
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{"play not launched", false, nil, http.MethodPost, "/play", "", http.StatusConflict, "not_launched"},
+		{"pause not launched", false, nil, http.MethodPost, "/pause", "", http.StatusConflict, "not_launched"},
+		{"next not launched", false, nil, http.MethodPost, "/next", "", http.StatusConflict, "not_launched"},
+		{"stop not launched", false, nil, http.MethodPost, "/stop", "", http.StatusConflict, "already_stopped"},
+		{"play playing", true, []string{"/play"}, http.MethodPost, "/play", "", http.StatusConflict, "already_playing"},
+		{"pause paused", true, nil, http.MethodPost, "/pause", "", http.StatusConflict, "already_paused"},
+		{"next at end", true, []string{"/next"}, http.MethodPost, "/next", "", http.StatusConflict, "end_of_playlist"},
+		{"prev at start", true, nil, http.MethodPost, "/prev", "", http.StatusConflict, "start_of_playlist"},
+		{"time out of range", true, nil, http.MethodPatch, "/time", `{"Time":61}`, http.StatusUnprocessableEntity, "time_out_of_range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+
+			ts := newTestServer(t, service.Config{
+				Logger: slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelError})),
+			})
+			pl := ts.playlist(t, "state", 60, 60)
+			base := fmt.Sprintf("/v1/playlist/%d", pl.Id)
+
+			if tt.launch {
+				ts.launch(t, pl)
+			}
+
+			for _, path := range tt.setup {
+				if rec := ts.do(t, http.MethodPost, base+path, ""); rec.Code != http.StatusOK {
+					t.Fatalf("%s status %d: %s", path, rec.Code, rec.Body.String())
+				}
+			}
+
+			rec := ts.do(t, tt.method, base+tt.path, tt.body)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+
+			if code := decodeError(t, rec).Code; code != tt.code {
+				t.Fatalf("code %q, want %q", code, tt.code)
+			}
+
+			if logs.Len() != 0 {
+				t.Fatalf("state error logged as server error: %s", logs.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gocloudcamp_test/internal/service"
+
+	"github.com/go-chi/render"
+)
+
+var (
+	ErrInvalidIfMatch  = errors.New("if-match must be a playlist etag")
+	ErrIfMatchRequired = errors.New("if-match header is required")
+)
+
+func etag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+func parseIfMatch(value string) (uint, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "W/")
+
+	version, err := strconv.ParseUint(strings.Trim(value, `"`), 10, 0)
+	if err != nil {
+		return 0, ErrInvalidIfMatch
+	}
+
+	return uint(version), nil
+}
+
+type etagWriter struct {
+	http.ResponseWriter
+	s           *service.Service
+	id          uint
+	wroteHeader bool
+}
+
+func (w *etagWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+
+		if pl, err := w.s.GetPlaylist(w.id); err == nil && status < http.StatusMultipleChoices {
+			w.Header().Set("ETag", etag(pl.Version()))
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *etagWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func requiresIfMatch(method string) bool {
+	return method == http.MethodPatch || method == http.MethodDelete
+}
+
+func requireVersion(s *service.Service) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			id, err := parseId(r, "id")
+			if err != nil {
+				render.Render(w, r, responseInvalidRequest(err))
+
+				return
+			}
+
+			w = &etagWriter{ResponseWriter: w, s: s, id: id}
+
+			value := r.Header.Get("If-Match")
+			if value == "" || value == "*" {
+				if value == "" && requiresIfMatch(r.Method) && s.Config().RequireIfMatch {
+					render.Render(w, r, responsePreconditionRequired(ErrIfMatchRequired))
+
+					return
+				}
+
+				unlock, err := s.LockPlaylist(id)
+				if err != nil {
+					render.Render(w, r, responseError(err))
+
+					return
+				}
+				defer unlock()
+
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			version, err := parseIfMatch(value)
+			if err != nil {
+				render.Render(w, r, responseInvalidRequest(err))
+
+				return
+			}
+
+			unlock, err := s.LockVersion(id, version)
+			if err != nil {
+				if errors.Is(err, service.ErrVersionMismatch) {
+					render.Render(w, r, responsePreconditionFailed(err))
+
+					return
+				}
+
+				render.Render(w, r, responseError(err))
+
+				return
+			}
+			defer unlock()
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
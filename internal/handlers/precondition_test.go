@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"gocloudcamp_test/internal/service"
+)
+
+func TestRequireVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		ifMatch func(version uint) string
+		status  int
+		code    string
+		bumped  bool
+	}{
+		{"missing if-match", http.MethodPatch, "/name", `{"Name":"renamed"}`, nil, http.StatusPreconditionRequired, "if_match_required", false},
+		{"stale if-match", http.MethodPatch, "/name", `{"Name":"renamed"}`, func(v uint) string { return etag(v - 1) }, http.StatusPreconditionFailed, "version_mismatch", false},
+		{"malformed if-match", http.MethodPatch, "/name", `{"Name":"renamed"}`, func(uint) string { return "abc" }, http.StatusBadRequest, "", false},
+		{"current if-match", http.MethodPatch, "/name", `{"Name":"renamed"}`, func(v uint) string { return etag(v) }, http.StatusOK, "", true},
+		{"weak if-match", http.MethodPatch, "/name", `{"Name":"renamed"}`, func(v uint) string { return "W/" + etag(v) }, http.StatusOK, "", true},
+		{"wildcard if-match", http.MethodPatch, "/name", `{"Name":"renamed"}`, func(uint) string { return "*" }, http.StatusOK, "", true},
+		{"stale delete", http.MethodDelete, "", "", func(v uint) string { return etag(v + 1) }, http.StatusPreconditionFailed, "version_mismatch", false},
+		{"stale post", http.MethodPost, "/swap", "", func(v uint) string { return etag(v - 1) }, http.StatusPreconditionFailed, "version_mismatch", false},
+		{"post without if-match", http.MethodPost, "/swap", "", nil, http.StatusOK, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(t, service.Config{RequireIfMatch: true})
+			pl := ts.playlist(t, "playlist", 10, 20)
+			version := pl.Version()
+
+			body := tt.body
+			if tt.path == "/swap" {
+				ids := pl.GetSongsList()
+				body = fmt.Sprintf(`{"A":%d,"B":%d}`, ids[0].Id, ids[1].Id)
+			}
+
+			var header []string
+			if tt.ifMatch != nil {
+				header = []string{"If-Match", tt.ifMatch(version)}
+			}
+
+			rec := ts.do(t, tt.method, fmt.Sprintf("/v1/playlist/%d%s", pl.Id, tt.path), body, header...)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+
+			if tt.code != "" {
+				if code := decodeError(t, rec).Code; code != tt.code {
+					t.Fatalf("code %q, want %q", code, tt.code)
+				}
+			}
+
+			if bumped := pl.Version() != version; bumped != tt.bumped {
+				t.Fatalf("version %d -> %d, bumped %t, want %t", version, pl.Version(), bumped, tt.bumped)
+			}
+
+			if tt.bumped {
+				if got := rec.Header().Get("ETag"); got != etag(pl.Version()) {
+					t.Fatalf("etag %q, want %q", got, etag(pl.Version()))
+				}
+			}
+		})
+	}
+}
+
+func TestRequireVersionOptional(t *testing.T) {
+	ts := newTestServer(t, service.Config{})
+	pl := ts.playlist(t, "playlist", 10)
+
+	rec := ts.do(t, http.MethodPatch, fmt.Sprintf("/v1/playlist/%d/name", pl.Id), `{"Name":"renamed"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+}
+
+func TestGetPlaylistEtag(t *testing.T) {
+	ts := newTestServer(t, service.Config{RequireIfMatch: true})
+	pl := ts.playlist(t, "playlist", 10)
+
+	rec := ts.do(t, http.MethodGet, fmt.Sprintf("/v1/playlist/%d", pl.Id), "")
+	if got := rec.Header().Get("ETag"); got != etag(pl.Version()) {
+		t.Fatalf("etag %q, want %q", got, etag(pl.Version()))
+	}
+
+	rec = ts.do(t, http.MethodPatch, fmt.Sprintf("/v1/playlist/%d/name", pl.Id), `{"Name":"renamed"}`, "If-Match", rec.Header().Get("ETag"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d after revalidation, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	rec = ts.do(t, http.MethodPatch, fmt.Sprintf("/v1/playlist/%d/name", pl.Id), `{"Name":"clobbered"}`, "If-Match", etag(pl.Version()-1))
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Fatalf("status %d with stale etag, want %d", rec.Code, http.StatusPreconditionFailed)
+	}
+
+	if name := pl.Status().Name; name != "renamed" {
+		t.Fatalf("name %q after conflict, want %q", name, "renamed")
+	}
+}
+
+func TestPlaybackSettingsBumpVersion(t *testing.T) {
+	tests := []struct {
+		path string
+		body string
+	}{
+		{"/time", `{"Time":5}`},
+		{"/shuffle", `{"Shuffle":true}`},
+		{"/repeat", `{"Mode":"all"}`},
+		{"/speed", `{"Speed":1.5}`},
+		{"/webhook", `{"Url":"http://example.com/hook"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.TrimPrefix(tt.path, "/"), func(t *testing.T) {
+			ts := newTestServer(t, service.Config{RequireIfMatch: true})
+			pl := ts.playlist(t, "playlist", 10, 20)
+			ts.launch(t, pl)
+
+			version := pl.Version()
+			target := fmt.Sprintf("/v1/playlist/%d%s", pl.Id, tt.path)
+
+			ts.store.Reset()
+
+			rec := ts.do(t, http.MethodPatch, target, tt.body, "If-Match", etag(version))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+			}
+
+			if pl.Version() != version+1 {
+				t.Fatalf("version %d, want %d", pl.Version(), version+1)
+			}
+
+			if got := rec.Header().Get("ETag"); got != etag(pl.Version()) {
+				t.Fatalf("etag %q, want %q", got, etag(pl.Version()))
+			}
+
+			var persisted bool
+
+			for _, query := range ts.store.Queries() {
+				if strings.HasPrefix(query, "UPDATE") && strings.Contains(query, `"version"=version + 1`) {
+					persisted = true
+				}
+			}
+
+			if !persisted {
+				t.Fatalf("version bump not written to the database: %q", ts.store.Queries())
+			}
+
+			rec = ts.do(t, http.MethodPatch, target, tt.body, "If-Match", etag(version))
+			if rec.Code != http.StatusPreconditionFailed {
+				t.Fatalf("status %d with the previous etag, want %d", rec.Code, http.StatusPreconditionFailed)
+			}
+		})
+	}
+}
+
+func TestMutationWithoutIfMatchTakesLock(t *testing.T) {
+	ts := newTestServer(t, service.Config{})
+	pl := ts.playlist(t, "playlist", 10, 20)
+
+	ids := pl.GetSongsList()
+
+	unlock, err := ts.s.LockVersion(pl.Id, pl.Version())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan int)
+
+	go func() {
+		rec := ts.do(t, http.MethodPost, fmt.Sprintf("/v1/playlist/%d/swap", pl.Id), fmt.Sprintf(`{"A":%d,"B":%d}`, ids[0].Id, ids[1].Id))
+
+		done <- rec.Code
+	}()
+
+	select {
+	case code := <-done:
+		unlock()
+
+		t.Fatalf("swap finished with status %d while the playlist was locked", code)
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	unlock()
+
+	select {
+	case code := <-done:
+		if code != http.StatusOK {
+			t.Fatalf("status %d, want %d", code, http.StatusOK)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("swap still waiting after the lock was released")
+	}
+}
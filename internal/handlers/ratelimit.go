@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+const rateCleanupInterval = time.Minute
+
+var ErrRateLimited = errors.New("too many requests, retry later")
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type rateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*bucket
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = int(math.Ceil(rate))
+	}
+
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+func (rl *rateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &bucket{tokens: rl.burst, last: now}
+		rl.buckets[key] = b
+	}
+
+	b.tokens = math.Min(rl.burst, b.tokens+now.Sub(b.last).Seconds()*rl.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+
+		return true, 0
+	}
+
+	return false, time.Duration((1 - b.tokens) / rl.rate * float64(time.Second))
+}
+
+func (rl *rateLimiter) cleanup(now time.Time) int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	idle := time.Duration(rl.burst / rl.rate * float64(time.Second))
+	removed := 0
+
+	for key, b := range rl.buckets {
+		if now.Sub(b.last) >= idle {
+			delete(rl.buckets, key)
+			removed++
+		}
+	}
+
+	return removed
+}
+
+func (rl *rateLimiter) run(ctx context.Context) {
+	ticker := time.NewTicker(rateCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if removed := rl.cleanup(now); removed > 0 {
+				log.Printf("ratelimit | cleanup | removed %d", removed)
+			}
+		}
+	}
+}
+
+func parseTrustedProxies(proxies []string) []netip.Prefix {
+	var prefixes []netip.Prefix
+
+	for _, proxy := range proxies {
+		if prefix, err := netip.ParsePrefix(proxy); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+
+			continue
+		}
+
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			log.Printf("ratelimit | invalid trusted proxy | %s", proxy)
+
+			continue
+		}
+
+		prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+
+	return prefixes
+}
+
+func isTrusted(trusted []netip.Prefix, ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+
+	for _, prefix := range trusted {
+		if prefix.Contains(addr.Unmap()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func clientIP(r *http.Request, trusted []netip.Prefix) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	if !isTrusted(trusted, ip) {
+		return ip
+	}
+
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+
+		if !isTrusted(trusted, hop) {
+			return hop
+		}
+
+		ip = hop
+	}
+
+	return ip
+}
+
+func requestRateLimit(ctx context.Context, rate float64, burst int, proxies []string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if rate <= 0 {
+			return next
+		}
+
+		limiter := newRateLimiter(rate, burst)
+		trusted := parseTrustedProxies(proxies)
+
+		go limiter.run(ctx)
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/ping" {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			ok, wait := limiter.allow(clientIP(r, trusted), time.Now())
+			if !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+
+				render.Render(w, r, responseTooManyRequests(ErrRateLimited))
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"gocloudcamp_test/internal/service"
+
+	"github.com/go-chi/render"
+)
+
+func requestRecoverer(s *service.Service) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+
+				s.LogError(
+					"panic",
+					fmt.Errorf("%v", rec),
+					slog.String("endpoint", routePattern(r)),
+					slog.String("stack", string(debug.Stack())),
+				)
+
+				ctx := context.WithValue(r.Context(), render.ContentTypeCtxKey, acceptedContentType(r.Header.Get("Accept")))
+
+				render.Render(w, r.WithContext(ctx), responseInternalError(ErrInternal))
+			}()
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gocloudcamp_test/internal/service"
+
+	"github.com/go-chi/chi"
+)
+
+func TestRequestRecoverer(t *testing.T) {
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+		decode      func([]byte, any) error
+	}{
+		{"default", "", "application/json", json.Unmarshal},
+		{"json", "application/json", "application/json", json.Unmarshal},
+		{"xml", "application/xml", "application/xml", xml.Unmarshal},
+		{"json api", mediaTypeJSONAPI, mediaTypeJSONAPI, json.Unmarshal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(t, service.Config{})
+
+			ts.handler.(*chi.Mux).Get("/panic", func(w http.ResponseWriter, r *http.Request) {
+				panic("boom")
+			})
+
+			var header []string
+			if tt.accept != "" {
+				header = []string{"Accept", tt.accept}
+			}
+
+			rec := ts.do(t, http.MethodGet, "/panic", "", header...)
+
+			if rec.Code != http.StatusInternalServerError {
+				t.Fatalf("status %d, want %d", rec.Code, http.StatusInternalServerError)
+			}
+
+			if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, tt.contentType) {
+				t.Fatalf("content type %q, want %q", ct, tt.contentType)
+			}
+
+			var body any
+			if err := tt.decode(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body %q: %v", rec.Body.String(), err)
+			}
+
+			if !strings.Contains(rec.Body.String(), "internal_error") {
+				t.Fatalf("body %q does not carry the internal_error code", rec.Body.String())
+			}
+		})
+	}
+}
@@ -1,33 +1,55 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"sort"
+	"strconv"
+	"time"
 
+	"gocloudcamp_test/internal/build"
 	"gocloudcamp_test/internal/playlist"
+	"gocloudcamp_test/internal/service"
 
 	"github.com/go-chi/render"
 )
 
+type fieldError struct {
+	Field       string `json:"field" xml:"field"`
+	Code        string `json:"code" xml:"code"`
+	MessageText string `json:"message" xml:"message"`
+}
+
 type errorResponse struct {
-	HTTPStatusCode int    `json:"-"`
-	MessageText    string `json:"message,omitempty"`
-	ErrorText      string `json:"error,omitempty"`
+	HTTPStatusCode int          `json:"-" xml:"-"`
+	Code           string       `json:"code,omitempty" xml:"code,omitempty"`
+	MessageText    string       `json:"message,omitempty" xml:"message,omitempty"`
+	ErrorText      string       `json:"error,omitempty" xml:"error,omitempty"`
+	Errors         []fieldError `json:"errors,omitempty" xml:"errors>error,omitempty"`
+	RetryAfter     int          `json:"-" xml:"-"`
 }
 
 func (er *errorResponse) Render(w http.ResponseWriter, r *http.Request) error {
 	render.Status(r, er.HTTPStatusCode)
 
+	if er.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(er.RetryAfter))
+	}
+
 	return nil
 }
 
 var (
 	responseNotFound = &errorResponse{
 		HTTPStatusCode: http.StatusNotFound,
+		Code:           "route_not_found",
 		MessageText:    "invalid request",
 		ErrorText:      "route does not exist",
 	}
 	responseNotAllowed = &errorResponse{
 		HTTPStatusCode: http.StatusMethodNotAllowed,
+		Code:           "method_not_allowed",
 		MessageText:    "invalid request",
 		ErrorText:      "method is not valid",
 	}
@@ -36,23 +58,213 @@ var (
 func responseInvalidRequest(err error) render.Renderer {
 	return &errorResponse{
 		HTTPStatusCode: http.StatusBadRequest,
+		Code:           errorCode(err, "invalid_request"),
 		MessageText:    "invalid request",
 		ErrorText:      err.Error(),
 	}
 }
 
+func responseTooLarge(err error) render.Renderer {
+	return &errorResponse{
+		HTTPStatusCode: http.StatusRequestEntityTooLarge,
+		Code:           errorCode(err, "body_too_large"),
+		MessageText:    "invalid request",
+		ErrorText:      err.Error(),
+	}
+}
+
+func responseDecodeError(err error) render.Renderer {
+	var maxErr *http.MaxBytesError
+
+	if errors.As(err, &maxErr) {
+		return responseTooLarge(ErrRequestTooLarge)
+	}
+
+	return responseInvalidRequest(ErrRequestBody)
+}
+
+func responseTimeout(err error) render.Renderer {
+	return &errorResponse{
+		HTTPStatusCode: http.StatusServiceUnavailable,
+		Code:           errorCode(err, "request_timeout"),
+		MessageText:    "service unavailable",
+		ErrorText:      err.Error(),
+	}
+}
+
+func responseUnauthorized(err error) render.Renderer {
+	return &errorResponse{
+		HTTPStatusCode: http.StatusUnauthorized,
+		Code:           errorCode(err, "unauthorized"),
+		MessageText:    "unauthorized",
+		ErrorText:      err.Error(),
+	}
+}
+
+func responseForbidden(err error) render.Renderer {
+	return &errorResponse{
+		HTTPStatusCode: http.StatusForbidden,
+		Code:           errorCode(err, "forbidden"),
+		MessageText:    "forbidden",
+		ErrorText:      err.Error(),
+	}
+}
+
+func responseNotFoundError(err error) render.Renderer {
+	return &errorResponse{
+		HTTPStatusCode: http.StatusNotFound,
+		Code:           errorCode(err, "not_found"),
+		MessageText:    "not found",
+		ErrorText:      err.Error(),
+	}
+}
+
+func responseConflict(err error) render.Renderer {
+	return &errorResponse{
+		HTTPStatusCode: http.StatusConflict,
+		Code:           errorCode(err, "conflict"),
+		MessageText:    "conflict",
+		ErrorText:      err.Error(),
+	}
+}
+
+func responseTooManyRequests(err error) render.Renderer {
+	return &errorResponse{
+		HTTPStatusCode: http.StatusTooManyRequests,
+		Code:           errorCode(err, "too_many_requests"),
+		MessageText:    "too many requests",
+		ErrorText:      err.Error(),
+	}
+}
+
+func responsePreconditionFailed(err error) render.Renderer {
+	return &errorResponse{
+		HTTPStatusCode: http.StatusPreconditionFailed,
+		Code:           errorCode(err, "precondition_failed"),
+		MessageText:    "precondition failed",
+		ErrorText:      err.Error(),
+	}
+}
+
+func responsePreconditionRequired(err error) render.Renderer {
+	return &errorResponse{
+		HTTPStatusCode: http.StatusPreconditionRequired,
+		Code:           errorCode(err, "precondition_required"),
+		MessageText:    "precondition required",
+		ErrorText:      err.Error(),
+	}
+}
+
+func responseUnprocessable(err error) render.Renderer {
+	return &errorResponse{
+		HTTPStatusCode: http.StatusUnprocessableEntity,
+		Code:           errorCode(err, "invalid_entity"),
+		MessageText:    "invalid entity",
+		ErrorText:      err.Error(),
+	}
+}
+
+func responseValidation(err error) render.Renderer {
+	resp := &errorResponse{
+		HTTPStatusCode: http.StatusUnprocessableEntity,
+		Code:           errorCode(err, "invalid_entity"),
+		MessageText:    "invalid entity",
+		ErrorText:      err.Error(),
+	}
+
+	var verr *service.ValidationError
+
+	if errors.As(err, &verr) {
+		for _, fe := range verr.Fields {
+			resp.Errors = append(resp.Errors, fieldError{
+				Field:       fe.Field,
+				Code:        errorCode(fe.Err, "invalid_entity"),
+				MessageText: fe.Err.Error(),
+			})
+		}
+
+		if len(resp.Errors) > 0 {
+			resp.Code = resp.Errors[0].Code
+		}
+	}
+
+	return resp
+}
+
+func responseServiceUnavailable(err error) render.Renderer {
+	return &errorResponse{
+		HTTPStatusCode: http.StatusServiceUnavailable,
+		Code:           errorCode(err, "service_unavailable"),
+		MessageText:    "service unavailable",
+		ErrorText:      err.Error(),
+	}
+}
+
+const databaseRetryAfter = 5
+
+func responseDatabaseUnavailable(err error) render.Renderer {
+	return &errorResponse{
+		HTTPStatusCode: http.StatusServiceUnavailable,
+		Code:           errorCode(err, "service_unavailable"),
+		MessageText:    "service unavailable",
+		ErrorText:      service.ErrUnavailable.Error(),
+		RetryAfter:     databaseRetryAfter,
+	}
+}
+
 func responseInternalError(err error) render.Renderer {
 	return &errorResponse{
 		HTTPStatusCode: http.StatusInternalServerError,
+		Code:           errorCode(err, "internal_error"),
 		MessageText:    "something went wrong",
 		ErrorText:      err.Error(),
 	}
 }
 
+func isNotFound(err error) bool {
+	return errors.Is(err, service.ErrPlaylistNotFound) || errors.Is(err, playlist.ErrSongNotIn)
+}
+
+func isStateConflict(err error) bool {
+	return errors.Is(err, playlist.ErrNotProcessed) ||
+		errors.Is(err, playlist.ErrAlreadyProcessing) ||
+		errors.Is(err, playlist.ErrAlreadyStopped) ||
+		errors.Is(err, playlist.ErrAlreadyPlaying) ||
+		errors.Is(err, playlist.ErrAlreadyPaused)
+}
+
+func responseError(err error) render.Renderer {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return responseTimeout(ErrRequestTimeout)
+	}
+
+	if isNotFound(err) {
+		return responseNotFoundError(err)
+	}
+
+	if errors.Is(err, service.ErrUnavailable) {
+		return responseDatabaseUnavailable(err)
+	}
+
+	if errors.Is(err, service.ErrShuttingDown) {
+		return responseServiceUnavailable(err)
+	}
+
+	if isStateConflict(err) {
+		return responseConflict(err)
+	}
+
+	if errors.Is(err, playlist.ErrLargerTime) {
+		return responseUnprocessable(err)
+	}
+
+	return responseInternalError(err)
+}
+
 type messageResponse struct {
-	HTTPStatusCode int    `json:"-"`
-	MessageText    string `json:"message,omitempty"`
-	PlaylistId     uint   `json:"id,omitempty"`
+	HTTPStatusCode int    `json:"-" xml:"-"`
+	MessageText    string `json:"message,omitempty" xml:"message,omitempty"`
+	PlaylistId     uint   `json:"id,omitempty" xml:"id,omitempty"`
 }
 
 func (mr *messageResponse) Render(w http.ResponseWriter, r *http.Request) error {
@@ -61,14 +273,98 @@ func (mr *messageResponse) Render(w http.ResponseWriter, r *http.Request) error
 	return nil
 }
 
+type versionResponse struct {
+	HTTPStatusCode int `json:"-" xml:"-"`
+	build.Info
+}
+
+func (vr *versionResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, vr.HTTPStatusCode)
+
+	return nil
+}
+
+type healthResponse struct {
+	HTTPStatusCode int     `json:"-" xml:"-"`
+	Database       string  `json:"db" xml:"db"`
+	Latency        float64 `json:"latency_ms" xml:"latency_ms"`
+	ErrorText      string  `json:"error,omitempty" xml:"error,omitempty"`
+}
+
+func (hr *healthResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, hr.HTTPStatusCode)
+
+	return nil
+}
+
+type countResponse struct {
+	HTTPStatusCode int    `json:"-" xml:"-"`
+	MessageText    string `json:"message,omitempty" xml:"message,omitempty"`
+	PlaylistId     uint   `json:"id,omitempty" xml:"id,omitempty"`
+	Count          int    `json:"count" xml:"count"`
+}
+
+func (cr *countResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, cr.HTTPStatusCode)
+
+	return nil
+}
+
 type playlistData struct {
-	Status playlist.Status `json:"status,omitempty"`
-	Songs  []playlist.Song `json:"songs,omitempty"`
+	Status        *playlist.Status  `json:"status,omitempty" xml:"status,omitempty"`
+	CurrentSong   *playlist.Current `json:"current_song,omitempty" xml:"current_song,omitempty"`
+	TotalDuration *uint64           `json:"total_duration,omitempty" xml:"total_duration,omitempty"`
+	Volume        *uint             `json:"volume,omitempty" xml:"volume,omitempty"`
+	Songs         []playlist.Song   `json:"songs,omitempty" xml:"songs>song,omitempty"`
+	SongCount     *int              `json:"song_count,omitempty" xml:"song_count,omitempty"`
+	SongsUrl      string            `json:"songs_url,omitempty" xml:"songs_url,omitempty"`
+	Links         halLinks          `json:"_links,omitempty" xml:"-"`
+	id            uint
+}
+
+func newPlaylistData(pl *playlist.Playlist) playlistData {
+	return selectPlaylistData(pl, nil, true, "")
+}
+
+func selectPlaylistData(pl *playlist.Playlist, fields fieldSet, expand bool, base string) playlistData {
+	data := playlistData{id: pl.Id}
+
+	if fields.has("status") {
+		st := pl.Status()
+		data.Status = &st
+	}
+
+	if fields.has("current_song") {
+		data.CurrentSong = pl.Current()
+	}
+
+	if fields.has("total_duration") {
+		total := pl.TotalDuration()
+		data.TotalDuration = &total
+	}
+
+	if fields.has("volume") {
+		volume := pl.Volume()
+		data.Volume = &volume
+	}
+
+	if fields.has("song_count") {
+		count := pl.SongCount()
+		data.SongCount = &count
+	}
+
+	if fields.has("songs") && expand {
+		data.Songs = pl.GetSongsList()
+	} else if fields.has("songs") {
+		data.SongsUrl = base + playlistLocation(pl.Id) + "/songs"
+	}
+
+	return data
 }
 
 type playlistResponse struct {
-	HTTPStatusCode int          `json:"-"`
-	Playlist       playlistData `json:"playlist,omitempty"`
+	HTTPStatusCode int          `json:"-" xml:"-"`
+	Playlist       playlistData `json:"playlist,omitempty" xml:"playlist,omitempty"`
 }
 
 func (pr *playlistResponse) Render(w http.ResponseWriter, r *http.Request) error {
@@ -77,9 +373,182 @@ func (pr *playlistResponse) Render(w http.ResponseWriter, r *http.Request) error
 	return nil
 }
 
+type songsResponse struct {
+	HTTPStatusCode int             `json:"-" xml:"-"`
+	PlaylistId     uint            `json:"id,omitempty" xml:"id,omitempty"`
+	Songs          []playlist.Song `json:"songs,omitempty" xml:"songs>song,omitempty"`
+	Skipped        int             `json:"skipped,omitempty" xml:"skipped,omitempty"`
+}
+
+func (sr *songsResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, sr.HTTPStatusCode)
+
+	return nil
+}
+
+type songResponse struct {
+	HTTPStatusCode int           `json:"-" xml:"-"`
+	MessageText    string        `json:"message,omitempty" xml:"message,omitempty"`
+	PlaylistId     uint          `json:"id,omitempty" xml:"id,omitempty"`
+	Song           playlist.Song `json:"song" xml:"song"`
+}
+
+func (sr *songResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, sr.HTTPStatusCode)
+
+	return nil
+}
+
+type songsPageResponse struct {
+	HTTPStatusCode int             `json:"-" xml:"-"`
+	PlaylistId     uint            `json:"id,omitempty" xml:"id,omitempty"`
+	Songs          []playlist.Song `json:"songs" xml:"songs>song"`
+	NextCursor     string          `json:"next_cursor,omitempty" xml:"next_cursor,omitempty"`
+}
+
+func (sr *songsPageResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, sr.HTTPStatusCode)
+
+	return nil
+}
+
+type matchesResponse struct {
+	HTTPStatusCode int                 `json:"-" xml:"-"`
+	Songs          []service.SongMatch `json:"songs" xml:"songs>match"`
+}
+
+func (mr *matchesResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, mr.HTTPStatusCode)
+
+	return nil
+}
+
+type refsResponse struct {
+	HTTPStatusCode int                   `json:"-" xml:"-"`
+	Playlists      []service.PlaylistRef `json:"playlists" xml:"playlists>playlist"`
+}
+
+func (rr *refsResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, rr.HTTPStatusCode)
+
+	return nil
+}
+
+type historyResponse struct {
+	HTTPStatusCode int                     `json:"-" xml:"-"`
+	PlaylistId     uint                    `json:"id,omitempty" xml:"id,omitempty"`
+	History        []playlist.HistoryEntry `json:"history" xml:"history>entry"`
+}
+
+func (hr *historyResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, hr.HTTPStatusCode)
+
+	return nil
+}
+
+type sleepResponse struct {
+	HTTPStatusCode int        `json:"-" xml:"-"`
+	MessageText    string     `json:"message,omitempty" xml:"message,omitempty"`
+	PlaylistId     uint       `json:"id,omitempty" xml:"id,omitempty"`
+	StopAt         *time.Time `json:"stop_at,omitempty" xml:"stop_at,omitempty"`
+}
+
+func (sr *sleepResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, sr.HTTPStatusCode)
+
+	return nil
+}
+
+type scheduleResponse struct {
+	HTTPStatusCode int       `json:"-" xml:"-"`
+	MessageText    string    `json:"message,omitempty" xml:"message,omitempty"`
+	PlaylistId     uint      `json:"id,omitempty" xml:"id,omitempty"`
+	LaunchAt       time.Time `json:"launch_at" xml:"launch_at"`
+}
+
+func (sr *scheduleResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, sr.HTTPStatusCode)
+
+	return nil
+}
+
+type bulkError struct {
+	PlaylistId uint   `json:"id" xml:"id"`
+	Code       string `json:"code" xml:"code"`
+	ErrorText  string `json:"error" xml:"error"`
+}
+
+type bulkResponse struct {
+	HTTPStatusCode int         `json:"-" xml:"-"`
+	Affected       int         `json:"affected" xml:"affected"`
+	Errors         []bulkError `json:"errors,omitempty" xml:"errors>error,omitempty"`
+}
+
+func newBulkResponse(result service.BulkResult) *bulkResponse {
+	resp := &bulkResponse{
+		HTTPStatusCode: http.StatusOK,
+		Affected:       result.Affected,
+	}
+
+	for id, err := range result.Errors {
+		resp.Errors = append(resp.Errors, bulkError{
+			PlaylistId: id,
+			Code:       errorCode(err, "internal_error"),
+			ErrorText:  err.Error(),
+		})
+	}
+
+	sort.Slice(resp.Errors, func(i, j int) bool { return resp.Errors[i].PlaylistId < resp.Errors[j].PlaylistId })
+
+	return resp
+}
+
+func (br *bulkResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, br.HTTPStatusCode)
+
+	return nil
+}
+
+type batchResponse struct {
+	HTTPStatusCode int    `json:"-" xml:"-"`
+	Ids            []uint `json:"ids" xml:"ids>id"`
+}
+
+func (br *batchResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, br.HTTPStatusCode)
+
+	return nil
+}
+
+type statsResponse struct {
+	HTTPStatusCode int `json:"-" xml:"-"`
+	*service.Stats
+}
+
+func (sr *statsResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, sr.HTTPStatusCode)
+
+	return nil
+}
+
+type workersResponse struct {
+	HTTPStatusCode int `json:"-" xml:"-"`
+	*service.Workers
+}
+
+func (wr *workersResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, wr.HTTPStatusCode)
+
+	return nil
+}
+
 type allResponse struct {
-	HTTPStatusCode int            `json:"-"`
-	Playlists      []playlistData `json:"playlists,omitempty"`
+	HTTPStatusCode int            `json:"-" xml:"-"`
+	Total          int            `json:"total" xml:"total"`
+	Playlists      []playlistData `json:"playlists,omitempty" xml:"playlists>playlist,omitempty"`
+	Links          halLinks       `json:"_links,omitempty" xml:"-"`
+	offset         int
+	limit          int
 }
 
 func (ar *allResponse) Render(w http.ResponseWriter, r *http.Request) error {
@@ -87,3 +556,39 @@ func (ar *allResponse) Render(w http.ResponseWriter, r *http.Request) error {
 
 	return nil
 }
+
+type shareResponse struct {
+	HTTPStatusCode int    `json:"-" xml:"-"`
+	PlaylistId     uint   `json:"id" xml:"id"`
+	Token          string `json:"token" xml:"token"`
+	Url            string `json:"url" xml:"url"`
+}
+
+func (sr *shareResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, sr.HTTPStatusCode)
+
+	return nil
+}
+
+type timeResponse struct {
+	HTTPStatusCode int  `json:"-" xml:"-"`
+	Elapsed        uint `json:"elapsed" xml:"elapsed"`
+	Duration       uint `json:"duration" xml:"duration"`
+}
+
+func (tr *timeResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, tr.HTTPStatusCode)
+
+	return nil
+}
+
+type remainingResponse struct {
+	HTTPStatusCode int    `json:"-" xml:"-"`
+	Remaining      uint64 `json:"remaining" xml:"remaining"`
+}
+
+func (rr *remainingResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, rr.HTTPStatusCode)
+
+	return nil
+}
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"gocloudcamp_test/internal/service"
+)
+
+func TestSongDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration string
+		status   int
+		code     string
+	}{
+		{"zero", "0", http.StatusUnprocessableEntity, "invalid_duration"},
+		{"upper bound", "86400", 0, ""},
+		{"above upper bound", "86401", http.StatusUnprocessableEntity, "invalid_duration"},
+		{"overflow", "18446744073709551615", http.StatusUnprocessableEntity, "invalid_duration"},
+		{"negative", "-1", http.StatusBadRequest, "invalid_body"},
+	}
+
+	for _, tt := range tests {
+		for _, edit := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s edit %t", tt.name, edit), func(t *testing.T) {
+				if edit && tt.duration == "0" {
+					t.Skip("zero duration keeps the current value on edit")
+				}
+
+				ts := newTestServer(t, service.Config{})
+				pl := ts.playlist(t, "duration", 60)
+
+				song := fmt.Sprintf(`{"Duration":%s}`, tt.duration)
+
+				method, target, body := http.MethodPost, fmt.Sprintf("/v1/playlist/%d/song", pl.Id), "["+song+"]"
+				if edit {
+					method, target, body = http.MethodPatch, fmt.Sprintf("/v1/playlist/%d/song/%d", pl.Id, pl.GetSongsList()[0].Id), song
+				}
+
+				rec := ts.do(t, method, target, body)
+
+				if tt.code == "" {
+					if rec.Code >= http.StatusMultipleChoices {
+						t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+					}
+
+					return
+				}
+
+				if rec.Code != tt.status {
+					t.Fatalf("status %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+				}
+
+				if code := decodeError(t, rec).Code; code != tt.code {
+					t.Fatalf("code %q, want %q", code, tt.code)
+				}
+			})
+		}
+	}
+}
+
+func TestSongDurationFormat(t *testing.T) {
+	tests := []struct {
+		duration string
+		want     uint
+		human    string
+	}{
+		{`"3:45"`, 225, "3:45"},
+		{`"1:02:03"`, 3723, "1:02:03"},
+		{`225`, 225, "3:45"},
+		{`"3:4x"`, 0, ""},
+		{`"3:75"`, 0, ""},
+		{`"1:2:3:4"`, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.duration, func(t *testing.T) {
+			ts := newTestServer(t, service.Config{})
+			pl := ts.playlist(t, "duration")
+
+			rec := ts.do(t, http.MethodPost, fmt.Sprintf("/v1/playlist/%d/song", pl.Id), fmt.Sprintf(`[{"Duration":%s}]`, tt.duration))
+
+			if tt.want == 0 {
+				if rec.Code != http.StatusBadRequest {
+					t.Fatalf("status %d, want %d: %s", rec.Code, http.StatusBadRequest, rec.Body.String())
+				}
+
+				if code := decodeError(t, rec).Code; code != "invalid_body" {
+					t.Fatalf("code %q, want %q", code, "invalid_body")
+				}
+
+				return
+			}
+
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("status %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+			}
+
+			var resp struct {
+				Songs []struct {
+					Duration      uint
+					DurationHuman string `json:"duration_human"`
+				} `json:"songs"`
+			}
+
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(resp.Songs) != 1 || resp.Songs[0].Duration != tt.want || resp.Songs[0].DurationHuman != tt.human {
+				t.Fatalf("songs %+v, want duration %d (%s)", resp.Songs, tt.want, tt.human)
+			}
+		})
+	}
+}
+
+func TestSongsTagFilter(t *testing.T) {
+	ts := newTestServer(t, service.Config{})
+	pl := ts.playlist(t, "tags")
+
+	songs := `[
+		{"Name":"a","Duration":60,"Tags":[" Rock ","jazz"]},
+		{"Name":"b","Duration":60},
+		{"Name":"c","Duration":60,"Tags":["ROCK"]},
+		{"Name":"d","Duration":60,"Tags":[]}
+	]`
+
+	if rec := ts.do(t, http.MethodPost, fmt.Sprintf("/v1/playlist/%d/song", pl.Id), songs); rec.Code != http.StatusCreated {
+		t.Fatalf("add songs status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"a", "b", "c", "d"}},
+		{"?tag=rock", []string{"a", "c"}},
+		{"?tag=ROCK", []string{"a", "c"}},
+		{"?tag=%20jazz%20", []string{"a"}},
+		{"?tag=pop", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := ts.do(t, http.MethodGet, fmt.Sprintf("/v1/playlist/%d/songs%s", pl.Id, tt.query), "")
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+			}
+
+			var resp struct {
+				Songs []struct {
+					Name string
+					Tags []string `json:"tags"`
+				} `json:"songs"`
+			}
+
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+
+			names := []string{}
+
+			for _, sn := range resp.Songs {
+				names = append(names, sn.Name)
+
+				switch sn.Name {
+				case "a":
+					if !reflect.DeepEqual(sn.Tags, []string{"rock", "jazz"}) {
+						t.Fatalf("song a tags %q, want normalized %q", sn.Tags, []string{"rock", "jazz"})
+					}
+				case "b", "d":
+					if len(sn.Tags) != 0 {
+						t.Fatalf("song %s tags %q, want none", sn.Name, sn.Tags)
+					}
+				}
+			}
+
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Fatalf("songs %q, want %q", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditSongTags(t *testing.T) {
+	ts := newTestServer(t, service.Config{})
+	pl := ts.playlist(t, "tags", 60)
+	sid := pl.GetSongsList()[0].Id
+
+	if rec := ts.do(t, http.MethodPatch, fmt.Sprintf("/v1/playlist/%d/song/%d", pl.Id, sid), `{"Tags":[" Pop "]}`); rec.Code != http.StatusOK {
+		t.Fatalf("edit song status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	sn, err := pl.GetSong(sid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(sn.Tags, []string{"pop"}) {
+		t.Fatalf("tags %q, want %q", sn.Tags, []string{"pop"})
+	}
+}
+
+func TestSongPlaylists(t *testing.T) {
+	ts := newTestServer(t, service.Config{})
+
+	ids := make([]uint, 2)
+
+	for i := range ids {
+		pl := ts.playlist(t, fmt.Sprintf("playlist %d", i))
+		ids[i] = pl.Id
+
+		body := `[{"Name":"road trip","Duration":60},{"Name":"road trip","Duration":60}]`
+		if rec := ts.do(t, http.MethodPost, fmt.Sprintf("/v1/playlist/%d/song", pl.Id), body); rec.Code != http.StatusCreated {
+			t.Fatalf("add songs status %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	ts.playlist(t, "other", 60)
+
+	rec := ts.do(t, http.MethodGet, "/v1/songs/road%20trip/playlists", "")
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp struct {
+		Playlists []service.PlaylistRef `json:"playlists"`
+	}
+
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []service.PlaylistRef{{Id: ids[0], Name: "playlist 0"}, {Id: ids[1], Name: "playlist 1"}}
+
+	if !reflect.DeepEqual(resp.Playlists, want) {
+		t.Fatalf("playlists %v, want %v", resp.Playlists, want)
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gocloudcamp_test/internal/playlist"
+)
+
+type playlistsWriter struct {
+	w      io.Writer
+	enc    *json.Encoder
+	fields fieldSet
+	expand bool
+	base   string
+	count  int
+}
+
+func newPlaylistsWriter(w io.Writer, total int, fields fieldSet, expand bool, base string) (*playlistsWriter, error) {
+	if _, err := fmt.Fprintf(w, `{"total":%d`, total); err != nil {
+		return nil, err
+	}
+
+	return &playlistsWriter{w: w, enc: json.NewEncoder(w), fields: fields, expand: expand, base: base}, nil
+}
+
+func (pw *playlistsWriter) WritePlaylist(pl *playlist.Playlist) error {
+	sep := ","
+	if pw.count == 0 {
+		sep = `,"playlists":[`
+	}
+
+	if _, err := io.WriteString(pw.w, sep); err != nil {
+		return err
+	}
+
+	pw.count++
+
+	return pw.enc.Encode(selectPlaylistData(pl, pw.fields, pw.expand, pw.base))
+}
+
+func (pw *playlistsWriter) Close() error {
+	end := "}\n"
+	if pw.count > 0 {
+		end = "]}\n"
+	}
+
+	_, err := io.WriteString(pw.w, end)
+
+	return err
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gocloudcamp_test/internal/service"
+)
+
+const streamPlaylists = 10000
+
+type chunkRecorder struct {
+	*httptest.ResponseRecorder
+	writes  int
+	largest int
+}
+
+func (cr *chunkRecorder) Write(b []byte) (int, error) {
+	cr.writes++
+
+	if len(b) > cr.largest {
+		cr.largest = len(b)
+	}
+
+	return cr.ResponseRecorder.Write(b)
+}
+
+func newStreamServer(tb testing.TB) http.Handler {
+	tb.Helper()
+
+	s := service.New(nil, service.Config{RequestTimeout: time.Minute})
+
+	for id := uint(1); id <= streamPlaylists; id++ {
+		if err := s.AddPlaylist(id, "playlist", ""); err != nil {
+			tb.Fatal(err)
+		}
+
+		pl, _ := s.GetPlaylist(id)
+
+		for sid := uint(1); sid <= 3; sid++ {
+			if err := pl.AddSong(id*10+sid, "song", 180); err != nil {
+				tb.Fatal(err)
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tb.Cleanup(cancel)
+
+	return New(ctx, s)
+}
+
+func TestGetAllStreams(t *testing.T) {
+	handler := newStreamServer(t)
+
+	rec := &chunkRecorder{ResponseRecorder: httptest.NewRecorder()}
+
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/playlist?limit=10000&expand=songs", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var resp struct {
+		Total     int
+		Playlists []json.RawMessage
+	}
+
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode streamed listing: %v", err)
+	}
+
+	if resp.Total != streamPlaylists || len(resp.Playlists) != streamPlaylists {
+		t.Fatalf("total %d with %d playlists, want %d", resp.Total, len(resp.Playlists), streamPlaylists)
+	}
+
+	if rec.writes < streamPlaylists {
+		t.Fatalf("listing written in %d chunks, want at least one per playlist", rec.writes)
+	}
+
+	if limit := 4096; rec.largest > limit {
+		t.Fatalf("largest chunk %d bytes, want at most %d: the response was buffered", rec.largest, limit)
+	}
+}
+
+func TestGetAllAllocs(t *testing.T) {
+	handler := newStreamServer(t)
+
+	allocs := testing.AllocsPerRun(3, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/playlist?limit=10000", nil))
+	})
+
+	if perPlaylist := allocs / streamPlaylists; perPlaylist > 50 {
+		t.Fatalf("%.0f allocations per playlist, want at most 50", perPlaylist)
+	}
+}
+
+func BenchmarkGetAll(b *testing.B) {
+	handler := newStreamServer(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/playlist?limit=10000", nil))
+	}
+}
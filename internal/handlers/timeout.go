@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var ErrRequestTimeout = errors.New("request timed out")
+
+var timeoutExempt = []string{"/ws", "/events", "/launch", "/export", "/debug/pprof/profile", "/debug/pprof/trace"}
+
+func requestTimeout(timeout time.Duration) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if timeout <= 0 {
+			return next
+		}
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			for _, suffix := range timeoutExempt {
+				if strings.HasSuffix(strings.TrimSuffix(r.URL.Path, "/"), suffix) {
+					next.ServeHTTP(w, r)
+
+					return
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
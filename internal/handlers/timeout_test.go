@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gocloudcamp_test/internal/service"
+
+	"github.com/go-chi/render"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		deadline bool
+	}{
+		{"regular route", "/v1/playlist", true},
+		{"launch", "/v1/playlist/1/launch", false},
+		{"websocket", "/v1/playlist/1/ws", false},
+		{"events", "/v1/playlist/1/events", false},
+		{"export", "/v1/playlist/1/export", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deadline, flusher bool
+
+			handler := requestTimeout(time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, deadline = r.Context().Deadline()
+				_, flusher = w.(http.Flusher)
+			}))
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if deadline != tt.deadline {
+				t.Fatalf("deadline %t, want %t", deadline, tt.deadline)
+			}
+
+			if !flusher {
+				t.Fatal("response writer lost http.Flusher, streamed responses would be buffered")
+			}
+		})
+	}
+}
+
+func TestRequestTimeoutExceeded(t *testing.T) {
+	handler := requestTimeout(time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+
+		render.Render(w, r, responseError(r.Context().Err()))
+	}))
+
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/playlist", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	if code := decodeError(t, rec).Code; code != "request_timeout" {
+		t.Fatalf("code %q, want %q", code, "request_timeout")
+	}
+}
+
+func TestRequestTimeoutStalledDatabase(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+	}{
+		{"rename", http.MethodPatch, "/v1/playlist/%d/name", `{"Name":"renamed"}`},
+		{"volume", http.MethodPatch, "/v1/playlist/%d/volume", `{"volume":50}`},
+		{"share", http.MethodPost, "/v1/playlist/%d/share", ""},
+		{"delete", http.MethodDelete, "/v1/playlist/%d", ""},
+		{"remove song", http.MethodDelete, "/v1/playlist/%d/song/%d", ""},
+		{"favorite song", http.MethodPost, "/v1/playlist/%d/song/%d/favorite", ""},
+		{"move song", http.MethodPost, "/v1/playlist/%d/song/%d/move", `{"Position":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(t, service.Config{RequestTimeout: time.Millisecond * 50})
+			pl := ts.playlist(t, "playlist", 60, 60)
+
+			ts.store.Stall(func(query string) bool {
+				return query != "COMMIT" && query != "ROLLBACK"
+			})
+
+			target := tt.target
+			switch strings.Count(target, "%d") {
+			case 1:
+				target = fmt.Sprintf(target, pl.Id)
+			case 2:
+				target = fmt.Sprintf(target, pl.Id, pl.GetSongsList()[0].Id)
+			}
+
+			start := time.Now()
+
+			rec := ts.do(t, tt.method, target, tt.body)
+
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Fatalf("request took %s, the database call ignored the request deadline", elapsed)
+			}
+
+			if rec.Code != http.StatusServiceUnavailable {
+				t.Fatalf("status %d, want %d: %s", rec.Code, http.StatusServiceUnavailable, rec.Body.String())
+			}
+
+			if code := decodeError(t, rec).Code; code != "request_timeout" {
+				t.Fatalf("code %q, want %q", code, "request_timeout")
+			}
+		})
+	}
+}
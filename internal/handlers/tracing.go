@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func requestTracer(tp trace.TracerProvider) func(next http.Handler) http.Handler {
+	tracer := tp.Tracer("gocloudcamp_test/internal/handlers")
+	propagator := propagation.TraceContext{}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+			ctx, span := tracer.Start(ctx, r.Method+" "+r.URL.Path, trace.WithSpanKind(trace.SpanKindServer))
+			defer span.End()
+
+			defer func() {
+				if rctx := chi.RouteContext(ctx); rctx != nil && rctx.RoutePattern() != "" {
+					span.SetName(r.Method + " " + rctx.RoutePattern())
+				}
+
+				span.SetAttributes(
+					attribute.String("http.method", r.Method),
+					attribute.String("http.target", redactedURI(r)),
+					attribute.Int("http.status_code", ww.Status()),
+				)
+
+				if ww.Status() >= http.StatusInternalServerError {
+					span.SetStatus(codes.Error, http.StatusText(ww.Status()))
+				}
+			}()
+
+			next.ServeHTTP(ww, r.WithContext(ctx))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
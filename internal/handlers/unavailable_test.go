@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gocloudcamp_test/internal/service"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestDatabaseUnavailable(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		method string
+		target string
+		body   string
+		status int
+		code   string
+	}{
+		{"create bad connection", driver.ErrBadConn, http.MethodPost, "/v1/playlist", `{"Name":"created"}`, http.StatusServiceUnavailable, "database_unavailable"},
+		{"rename connection failure", &pgconn.PgError{Code: "08006"}, http.MethodPatch, "/v1/playlist/%d/name", `{"Name":"renamed"}`, http.StatusServiceUnavailable, "database_unavailable"},
+		{"delete admin shutdown", &pgconn.PgError{Code: "57P01"}, http.MethodDelete, "/v1/playlist/%d", "", http.StatusServiceUnavailable, "database_unavailable"},
+		{"rename duplicate name", &pgconn.PgError{Code: "23505", ConstraintName: "idx_playlists_name_lower"}, http.MethodPatch, "/v1/playlist/%d/name", `{"Name":"renamed"}`, http.StatusConflict, "duplicate_name"},
+		{"create duplicate name", &pgconn.PgError{Code: "23505", ConstraintName: "idx_playlists_name_lower"}, http.MethodPost, "/v1/playlist", `{"Name":"created"}`, http.StatusConflict, "duplicate_name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(t, service.Config{})
+			pl := ts.playlist(t, "playlist", 60)
+
+			ts.store.Fail(func(query string) error {
+				if query == "BEGIN" || query == "COMMIT" || query == "ROLLBACK" {
+					return nil
+				}
+
+				return tt.err
+			})
+
+			target := tt.target
+			if strings.Contains(target, "%d") {
+				target = fmt.Sprintf(target, pl.Id)
+			}
+
+			rec := ts.do(t, tt.method, target, tt.body)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+
+			if code := decodeError(t, rec).Code; code != tt.code {
+				t.Fatalf("code %q, want %q", code, tt.code)
+			}
+
+			retryAfter := rec.Header().Get("Retry-After")
+
+			if tt.status == http.StatusServiceUnavailable && retryAfter != strconv.Itoa(databaseRetryAfter) {
+				t.Fatalf("Retry-After %q, want %d", retryAfter, databaseRetryAfter)
+			}
+
+			if tt.status != http.StatusServiceUnavailable && retryAfter != "" {
+				t.Fatalf("Retry-After %q on a %d response", retryAfter, tt.status)
+			}
+		})
+	}
+}
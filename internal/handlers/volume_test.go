@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"gocloudcamp_test/internal/service"
+)
+
+func TestVolume(t *testing.T) {
+	tests := []struct {
+		volume string
+		status int
+		want   uint
+	}{
+		{"0", http.StatusOK, 0},
+		{"1", http.StatusOK, 1},
+		{"99", http.StatusOK, 99},
+		{"100", http.StatusOK, 100},
+		{"-1", http.StatusUnprocessableEntity, 100},
+		{"101", http.StatusUnprocessableEntity, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.volume, func(t *testing.T) {
+			ts := newTestServer(t, service.Config{})
+			pl := ts.playlist(t, "volume", 60)
+
+			rec := ts.do(t, http.MethodPatch, fmt.Sprintf("/v1/playlist/%d/volume", pl.Id), fmt.Sprintf(`{"volume":%s}`, tt.volume))
+
+			if rec.Code != tt.status {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+
+			if tt.status != http.StatusOK {
+				if code := decodeError(t, rec).Code; code != "invalid_volume" {
+					t.Fatalf("code %q, want %q", code, "invalid_volume")
+				}
+			}
+
+			rec = ts.do(t, http.MethodGet, fmt.Sprintf("/v1/playlist/%d", pl.Id), "")
+
+			var resp struct {
+				Playlist struct {
+					Volume *uint `json:"volume"`
+				} `json:"playlist"`
+			}
+
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.Playlist.Volume == nil || *resp.Playlist.Volume != tt.want {
+				t.Fatalf("volume %v, want %d", resp.Playlist.Volume, tt.want)
+			}
+		})
+	}
+}
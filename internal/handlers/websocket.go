@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+
+	"gocloudcamp_test/internal/playlist"
+	"gocloudcamp_test/internal/service"
+
+	"github.com/go-chi/render"
+	"github.com/gorilla/websocket"
+)
+
+var upgrader = websocket.Upgrader{}
+
+type eventMessage struct {
+	Event    playlist.Event `json:"event"`
+	Playlist playlistData   `json:"playlist"`
+}
+
+func socketPlaylist(s *service.Service) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseId(r, "id")
+		if err != nil {
+			render.Render(w, r, responseInvalidRequest(err))
+
+			return
+		}
+
+		pl, err := s.GetPlaylist(id)
+		if err != nil {
+			render.Render(w, r, responseError(err))
+
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		events, unsubscribe := pl.Subscribe()
+		defer unsubscribe()
+
+		chanClosed := make(chan struct{})
+
+		go func() {
+			defer close(chanClosed)
+
+			for {
+				if _, _, err := conn.NextReader(); err != nil {
+					return
+				}
+			}
+		}()
+
+		for {
+			select {
+			case ev, ok := <-events:
+				if !ok {
+					conn.WriteMessage(
+						websocket.CloseMessage,
+						websocket.FormatCloseMessage(websocket.CloseNormalClosure, "playlist deleted"),
+					)
+
+					return
+				}
+
+				if err := conn.WriteJSON(&eventMessage{Event: ev, Playlist: newPlaylistData(pl)}); err != nil {
+					return
+				}
+			case <-chanClosed:
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"gocloudcamp_test/internal/metrics"
+
+	"github.com/go-chi/render"
+)
+
+var ErrWriteBusy = errors.New("too many concurrent write requests, retry later")
+
+func isWrite(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+
+	return true
+}
+
+func requestWriteLimit(limit int, wait time.Duration) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limit <= 0 {
+			return next
+		}
+
+		slots := make(chan struct{}, limit)
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if !isWrite(r.Method) {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			metrics.WriteQueue.Inc()
+
+			timer := time.NewTimer(wait)
+
+			select {
+			case slots <- struct{}{}:
+				timer.Stop()
+				metrics.WriteQueue.Dec()
+			case <-timer.C:
+				metrics.WriteQueue.Dec()
+
+				w.Header().Set("Retry-After", "1")
+
+				render.Render(w, r, responseServiceUnavailable(ErrWriteBusy))
+
+				return
+			case <-r.Context().Done():
+				timer.Stop()
+				metrics.WriteQueue.Dec()
+
+				return
+			}
+
+			defer func() { <-slots }()
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
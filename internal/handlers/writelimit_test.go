@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gocloudcamp_test/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRequestWriteLimit(t *testing.T) {
+	const limit = 2
+
+	var active, peak atomic.Int32
+
+	entered := make(chan struct{}, limit)
+	release := make(chan struct{})
+
+	handler := requestWriteLimit(limit, time.Millisecond*20)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			return
+		}
+
+		n := active.Add(1)
+		defer active.Add(-1)
+
+		for {
+			if p := peak.Load(); n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+
+		entered <- struct{}{}
+		<-release
+	}))
+
+	serve := func(method string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, "/v1/playlist", nil))
+
+		return rec
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < limit; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if rec := serve(http.MethodPost); rec.Code != http.StatusOK {
+				t.Errorf("admitted write status %d, want %d", rec.Code, http.StatusOK)
+			}
+		}()
+	}
+
+	for i := 0; i < limit; i++ {
+		<-entered
+	}
+
+	for _, method := range []string{http.MethodPost, http.MethodPatch, http.MethodDelete} {
+		rec := serve(method)
+
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Fatalf("%s over the cap status %d, want %d", method, rec.Code, http.StatusServiceUnavailable)
+		}
+
+		if rec.Header().Get("Retry-After") == "" {
+			t.Fatalf("%s over the cap without Retry-After", method)
+		}
+
+		if code := decodeError(t, rec).Code; code != "write_busy" {
+			t.Fatalf("code %q, want %q", code, "write_busy")
+		}
+	}
+
+	if rec := serve(http.MethodGet); rec.Code != http.StatusOK {
+		t.Fatalf("read status %d while writes are capped, want %d", rec.Code, http.StatusOK)
+	}
+
+	close(release)
+	wg.Wait()
+
+	if p := peak.Load(); p != limit {
+		t.Fatalf("%d concurrent writes, want %d", p, limit)
+	}
+
+	if rec := serve(http.MethodPost); rec.Code != http.StatusOK {
+		t.Fatalf("write after release status %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	if depth := testutil.ToFloat64(metrics.WriteQueue); depth != 0 {
+		t.Fatalf("write queue depth %v after release, want 0", depth)
+	}
+}
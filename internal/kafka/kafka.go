@@ -0,0 +1,48 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+
+	"gocloudcamp_test/internal/service"
+
+	"github.com/segmentio/kafka-go"
+)
+
+type Producer struct {
+	writer *kafka.Writer
+}
+
+func New(brokers []string, topic string) *Producer {
+	log.Printf("kafka | producer | topic %s", topic)
+
+	return &Producer{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			BatchTimeout: time.Millisecond * 100,
+			Async:        true,
+		},
+	}
+}
+
+func (p *Producer) Publish(ctx context.Context, ev service.PlaybackEvent) error {
+	value, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(strconv.FormatUint(uint64(ev.PlaylistId), 10)),
+		Value: value,
+		Time:  ev.Timestamp,
+	})
+}
+
+func (p *Producer) Close() error {
+	return p.writer.Close()
+}
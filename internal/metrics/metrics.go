@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	Requests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "player_http_requests_total",
+		Help: "Number of handled http requests",
+	}, []string{"endpoint", "method", "status"})
+
+	Latency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "player_http_request_duration_seconds",
+		Help:    "Latency of handled http requests",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"endpoint", "method"})
+
+	Playlists = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "player_playlists",
+		Help: "Number of loaded playlists",
+	})
+
+	LaunchedPlaylists = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "player_launched_playlists",
+		Help: "Number of currently launched playlists",
+	})
+
+	DroppedErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "player_dropped_error_log_events_total",
+		Help: "Number of error log events dropped because the error channel was full",
+	})
+
+	WriteQueue = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "player_write_queue_depth",
+		Help: "Number of write requests waiting for a free slot",
+	})
+)
+
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
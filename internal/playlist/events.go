@@ -0,0 +1,78 @@
+package playlist
+
+import (
+	"sync"
+)
+
+type Event string
+
+const (
+	EventLaunch  Event = "launch"
+	EventPlay    Event = "play"
+	EventPause   Event = "pause"
+	EventNext    Event = "next"
+	EventPrev    Event = "prev"
+	EventJump    Event = "jump"
+	EventRestart Event = "restart"
+	EventSwitch  Event = "switch"
+	EventStop    Event = "stop"
+)
+
+type subscribers struct {
+	sync.Mutex
+	closed bool
+	chans  map[chan Event]struct{}
+}
+
+func (pl *Playlist) Subscribe() (<-chan Event, func()) {
+	pl.subs.Lock()
+	defer pl.subs.Unlock()
+
+	ch := make(chan Event, 16)
+
+	if pl.subs.closed {
+		close(ch)
+
+		return ch, func() {}
+	}
+
+	if pl.subs.chans == nil {
+		pl.subs.chans = make(map[chan Event]struct{})
+	}
+
+	pl.subs.chans[ch] = struct{}{}
+
+	return ch, func() {
+		pl.subs.Lock()
+		defer pl.subs.Unlock()
+
+		if _, ok := pl.subs.chans[ch]; ok {
+			delete(pl.subs.chans, ch)
+			close(ch)
+		}
+	}
+}
+
+func (pl *Playlist) Close() {
+	pl.subs.Lock()
+	defer pl.subs.Unlock()
+
+	pl.subs.closed = true
+
+	for ch := range pl.subs.chans {
+		delete(pl.subs.chans, ch)
+		close(ch)
+	}
+}
+
+func (pl *Playlist) broadcast(ev Event) {
+	pl.subs.Lock()
+	defer pl.subs.Unlock()
+
+	for ch := range pl.subs.chans {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
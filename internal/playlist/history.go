@@ -0,0 +1,73 @@
+package playlist
+
+import "time"
+
+type HistoryEntry struct {
+	SongId   uint      `json:"song_id" xml:"song_id"`
+	Name     string    `json:"name" xml:"name"`
+	PlayedAt time.Time `json:"played_at" xml:"played_at"`
+}
+
+type history struct {
+	entries []HistoryEntry
+	next    int
+	count   int
+}
+
+func (h *history) add(entry HistoryEntry) {
+	if len(h.entries) == 0 {
+		return
+	}
+
+	h.entries[h.next] = entry
+	h.next = (h.next + 1) % len(h.entries)
+
+	if h.count < len(h.entries) {
+		h.count++
+	}
+}
+
+func (h *history) list(limit int) []HistoryEntry {
+	if limit > h.count {
+		limit = h.count
+	}
+
+	entries := make([]HistoryEntry, 0, limit)
+
+	for i := 1; i <= limit; i++ {
+		entries = append(entries, h.entries[(h.next-i+len(h.entries))%len(h.entries)])
+	}
+
+	return entries
+}
+
+func (h *history) reset() {
+	h.next = 0
+	h.count = 0
+}
+
+func (pl *Playlist) SetHistorySize(size int) {
+	pl.Lock()
+	defer pl.Unlock()
+
+	pl.history = history{entries: make([]HistoryEntry, size)}
+}
+
+func (pl *Playlist) History(limit int) []HistoryEntry {
+	pl.RLock()
+	defer pl.RUnlock()
+
+	return pl.history.list(limit)
+}
+
+func (pl *Playlist) record() {
+	if pl.curr == nil {
+		return
+	}
+
+	pl.history.add(HistoryEntry{
+		SongId:   pl.curr.Id,
+		Name:     pl.curr.Name,
+		PlayedAt: time.Now().UTC(),
+	})
+}
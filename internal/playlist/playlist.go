@@ -2,8 +2,11 @@ package playlist
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
+	"math/rand"
 	"sync"
 	"time"
 )
@@ -14,8 +17,8 @@ var (
 	ErrAlreadyStopped    = errors.New("playlist is already stopped")
 	ErrAlreadyPlaying    = errors.New("playlist is already playing")
 	ErrAlreadyPaused     = errors.New("playlist is already paused")
-	ErrSwitchLast        = errors.New("this is the last song")
-	ErrSwitchFirst       = errors.New("this is the first song")
+	ErrEndOfPlaylist     = errors.New("this is the last song")
+	ErrStartOfPlaylist   = errors.New("this is the first song")
 	ErrSongNotIn         = errors.New("song is not in playlist")
 	ErrSongIdTaken       = errors.New("song with this id is already in playlist")
 	ErrRemoveFromEmpty   = errors.New("playlist is empty")
@@ -23,8 +26,28 @@ var (
 	ErrRemoveNotIn       = errors.New("this song is not in playlist")
 	ErrEditCurrent       = errors.New("this is current song")
 	ErrLargerTime        = errors.New("time is larger than current song duration")
+	ErrInvalidRepeat     = errors.New("repeat mode must be one of off, one, all")
+	ErrInvalidSpeed      = errors.New("speed must be between 0.25 and 4")
+	ErrIndexOutOfRange   = errors.New("song index is out of range")
+	ErrSwapSame          = errors.New("can't swap song with itself")
+	ErrNoSongs           = errors.New("playlist has no songs")
 )
 
+type Repeat string
+
+const (
+	RepeatOff Repeat = "off"
+	RepeatOne Repeat = "one"
+	RepeatAll Repeat = "all"
+)
+
+const (
+	MinSpeed = 0.25
+	MaxSpeed = 4.0
+)
+
+const DefaultVolume = 100
+
 type Status struct {
 	Id          uint
 	Name        string
@@ -34,19 +57,73 @@ type Status struct {
 	CurrentId   uint
 	CurrentName string
 	Duration    uint
+	Shuffle     bool
+	Repeat      Repeat
+	Speed       float64
+	Queue       []uint
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type State string
+
+const (
+	StateStopped State = "stopped"
+	StatePaused  State = "paused"
+	StatePlaying State = "playing"
+)
+
+func (st Status) State() State {
+	switch {
+	case st.Playing:
+		return StatePlaying
+	case st.Processing:
+		return StatePaused
+	default:
+		return StateStopped
+	}
+}
+
+type Current struct {
+	Id    uint
+	Name  string
+	Index int
 }
 
 type Song struct {
-	Id       uint
-	Name     string
-	Duration uint
-	prev     *Song
-	next     *Song
+	Id        uint
+	Name      string
+	Duration  uint
+	PlayCount uint     `json:"play_count" xml:"play_count"`
+	Tags      []string `json:"tags,omitempty" xml:"tags>tag,omitempty"`
+	Favorite  bool     `json:"favorite" xml:"favorite"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	prev      *Song
+	next      *Song
+}
+
+func (sn Song) MarshalJSON() ([]byte, error) {
+	type song Song
+
+	return json.Marshal(struct {
+		song
+		DurationHuman string `json:"duration_human"`
+	}{song(sn), FormatDuration(sn.Duration)})
+}
+
+func FormatDuration(seconds uint) string {
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
 }
 
 type Playlist struct {
-	Id   uint
-	Name string
+	Id    uint
+	Name  string
+	Owner string
 	sync.RWMutex
 	processing bool
 	playing    bool
@@ -54,11 +131,24 @@ type Playlist struct {
 	head       *Song
 	tail       *Song
 	curr       *Song
-	chanPlay   chan struct{}
-	chanPaus   chan struct{}
-	chanNext   chan struct{}
-	chanPrev   chan struct{}
-	chanStop   chan struct{}
+	shuffle    bool
+	repeat     Repeat
+	speed      float64
+	step       time.Duration
+	partial    time.Duration
+	partialAt  *Song
+	partialPos uint
+	volume     uint
+	version    uint
+	order      []*Song
+	queue      []*Song
+	rnd        *rand.Rand
+	chanWake   chan struct{}
+	subs       subscribers
+	created    time.Time
+	updated    time.Time
+	plays      map[uint]uint
+	history    history
 }
 
 func New(id uint, name string) *Playlist {
@@ -70,19 +160,136 @@ func New(id uint, name string) *Playlist {
 		processing: false,
 		playing:    false,
 		time:       0,
-		chanPlay:   make(chan struct{}),
-		chanPaus:   make(chan struct{}),
-		chanNext:   make(chan struct{}),
-		chanPrev:   make(chan struct{}),
-		chanStop:   make(chan struct{}),
+		repeat:     RepeatOff,
+		speed:      1,
+		step:       time.Second,
+		volume:     DefaultVolume,
+		version:    1,
+		rnd:        rand.New(rand.NewSource(time.Now().UnixNano())),
+		chanWake:   make(chan struct{}, 1),
+		created:    now(),
+		updated:    now(),
+	}
+}
+
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+func (pl *Playlist) SetTimes(created time.Time, updated time.Time) {
+	pl.Lock()
+	defer pl.Unlock()
+
+	pl.created = created.UTC().Truncate(time.Second)
+	pl.updated = updated.UTC().Truncate(time.Second)
+}
+
+func (pl *Playlist) SetSongTimes(id uint, created time.Time, updated time.Time) error {
+	pl.Lock()
+	defer pl.Unlock()
+
+	song := pl.findSong(id)
+	if song == nil {
+		return ErrSongNotIn
+	}
+
+	song.CreatedAt = created.UTC().Truncate(time.Second)
+	song.UpdatedAt = updated.UTC().Truncate(time.Second)
+
+	return nil
+}
+
+func (pl *Playlist) SetPlayCount(id uint, count uint) error {
+	pl.Lock()
+	defer pl.Unlock()
+
+	song := pl.findSong(id)
+	if song == nil {
+		return ErrSongNotIn
+	}
+
+	song.PlayCount = count
+
+	return nil
+}
+
+func (pl *Playlist) SetSongTags(id uint, tags []string) error {
+	pl.Lock()
+	defer pl.Unlock()
+
+	song := pl.findSong(id)
+	if song == nil {
+		return ErrSongNotIn
+	}
+
+	song.Tags = append([]string(nil), tags...)
+
+	return nil
+}
+
+func (pl *Playlist) SetFavorite(id uint, favorite bool) error {
+	pl.Lock()
+	defer pl.Unlock()
+
+	song := pl.findSong(id)
+	if song == nil {
+		return ErrSongNotIn
+	}
+
+	song.Favorite = favorite
+
+	return nil
+}
+
+func (pl *Playlist) TakePlays() map[uint]uint {
+	pl.Lock()
+	defer pl.Unlock()
+
+	plays := pl.plays
+	pl.plays = nil
+
+	return plays
+}
+
+func (pl *Playlist) countPlay() {
+	if pl.curr == nil {
+		return
+	}
+
+	pl.curr.PlayCount++
+
+	if pl.plays == nil {
+		pl.plays = make(map[uint]uint)
 	}
+
+	pl.plays[pl.curr.Id]++
+}
+
+func (pl *Playlist) IsEmpty() bool {
+	pl.RLock()
+	defer pl.RUnlock()
+
+	return pl.head == nil
 }
 
 func (pl *Playlist) IsProcessing() bool {
+	pl.RLock()
+	defer pl.RUnlock()
+
 	return pl.processing
 }
 
+func (pl *Playlist) IsPlaying() bool {
+	pl.RLock()
+	defer pl.RUnlock()
+
+	return pl.playing
+}
+
 func (pl *Playlist) IsCurrent(id uint) bool {
+	pl.RLock()
+	defer pl.RUnlock()
+
 	if pl.curr == nil {
 		return false
 	}
@@ -91,14 +298,25 @@ func (pl *Playlist) IsCurrent(id uint) bool {
 }
 
 func (pl *Playlist) Process(ctx context.Context) {
-	pl.processing = true
+	pl.Lock()
 
-	log.Printf("playlist | id %d | active", pl.Id)
+	pl.processing = true
 
 	if pl.curr == nil {
 		pl.curr = pl.head
 	}
 
+	pl.record()
+
+	pl.Unlock()
+
+	log.Printf("playlist | id %d | active", pl.Id)
+
+	pl.broadcast(EventLaunch)
+
+	ticker := time.NewTicker(pl.interval())
+	defer ticker.Stop()
+
 	for {
 		if err := ctx.Err(); err != nil {
 			log.Printf("playlist | id %d | %v", pl.Id, err)
@@ -106,86 +324,137 @@ func (pl *Playlist) Process(ctx context.Context) {
 			break
 		}
 
-		if !pl.processing || pl.curr == nil {
+		pl.RLock()
+		processing, playing := pl.processing && pl.curr != nil, pl.playing
+		pl.RUnlock()
+
+		if !processing {
 			log.Printf("playlist | id %d | stopped", pl.Id)
 
 			break
 		}
 
-		if !pl.playing {
-			pl.processPause()
+		if !playing {
+			pl.processPause(ctx)
+
+			ticker.Reset(pl.interval())
 
 			continue
 		}
 
-		for pl.time <= pl.curr.Duration {
-			if ctx.Err() != nil {
-				break
-			}
+		pl.processPlay(ctx, ticker)
+	}
+
+	pl.Lock()
+
+	pl.playing = false
+	pl.processing = false
+	pl.queue = nil
+
+	pl.history.reset()
+
+	pl.Unlock()
+
+	log.Printf("playlist | id %d | inactive", pl.Id)
+
+	pl.broadcast(EventStop)
+}
+
+func (pl *Playlist) processPlay(ctx context.Context, ticker *time.Ticker) {
+	select {
+	case <-ctx.Done():
+		break
+	case <-pl.chanWake:
+		ticker.Reset(pl.interval())
+	case <-ticker.C:
+		pl.tick()
+	}
+}
+
+func (pl *Playlist) processPause(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+		break
+	case <-pl.chanWake:
+		break
+	}
+}
+
+func (pl *Playlist) tick() {
+	pl.Lock()
+	defer pl.Unlock()
+
+	if !pl.playing || pl.curr == nil {
+		return
+	}
 
-			switchRequested := pl.processPlay()
+	if pl.curr != pl.partialAt || pl.time != pl.partialPos {
+		pl.partial = 0
+	}
 
-			if switchRequested || !pl.playing || !pl.processing || pl.curr == nil {
-				break
-			}
+	pl.partial += time.Duration(float64(pl.step) * pl.speed)
 
-			if pl.time == pl.curr.Duration {
-				pl.switchNext()
+	for pl.partial >= time.Second && pl.curr != nil {
+		pl.partial -= time.Second
 
-				break
-			}
+		pl.time++
 
+		if pl.time < pl.curr.Duration {
 			log.Printf("playlist | id %d | playing | songid %d | time %d", pl.Id, pl.curr.Id, pl.time)
 
-			pl.time++
+			continue
 		}
+
+		pl.switchAuto()
+
+		pl.broadcast(EventSwitch)
 	}
 
-	pl.playing = false
-	pl.processing = false
+	pl.partialAt, pl.partialPos = pl.curr, pl.time
+}
 
-	log.Printf("playlist | id %d | inactive", pl.Id)
+func (pl *Playlist) interval() time.Duration {
+	pl.RLock()
+	defer pl.RUnlock()
+
+	return pl.step
 }
 
-func (pl *Playlist) processPlay() bool {
+func (pl *Playlist) wake() {
 	select {
-	case <-pl.chanPlay:
-		break
-	case <-pl.chanPaus:
-		pl.playing = false
-		break
-	case <-pl.chanNext:
-		return true
-	case <-pl.chanPrev:
-		return true
-	case <-pl.chanStop:
-		break
+	case pl.chanWake <- struct{}{}:
 	default:
-		time.Sleep(time.Second)
 	}
-
-	return false
 }
 
-func (pl *Playlist) processPause() {
-	select {
-	case <-pl.chanPlay:
-		pl.playing = true
-		break
-	case <-pl.chanPaus:
-		break
-	case <-pl.chanNext:
-		break
-	case <-pl.chanPrev:
-		break
-	case <-pl.chanStop:
-		break
+func (pl *Playlist) switchAuto() {
+	switch {
+	case len(pl.queue) > 0:
+		pl.switchQueued()
+	case pl.repeat == RepeatOne:
+		pl.time = 0
+
+		log.Printf("playlist | id %d | repeat | songid %d | duration %d", pl.Id, pl.curr.Id, pl.curr.Duration)
+	case pl.repeat == RepeatAll && pl.nextSong(pl.curr) == nil:
+		pl.switchFirst()
 	default:
+		pl.switchNext()
 	}
+
+	pl.countPlay()
+
+	pl.record()
+}
+
+func (pl *Playlist) switchFirst() {
+	pl.curr = pl.firstSong()
+	pl.time = 0
+
+	log.Printf("playlist | id %d | wrap | songid %d | duration %d", pl.Id, pl.curr.Id, pl.curr.Duration)
 }
 
 func (pl *Playlist) switchNext() {
-	pl.curr = pl.curr.next
+	pl.curr = pl.nextSong(pl.curr)
 	pl.time = 0
 
 	if pl.curr != nil {
@@ -194,8 +463,12 @@ func (pl *Playlist) switchNext() {
 }
 
 func (pl *Playlist) Play() error {
-	pl.RLock()
-	defer pl.RUnlock()
+	pl.Lock()
+	defer pl.Unlock()
+
+	if pl.head == nil {
+		return ErrNoSongs
+	}
 
 	if !pl.processing {
 		return ErrNotProcessed
@@ -205,18 +478,20 @@ func (pl *Playlist) Play() error {
 		return ErrAlreadyPlaying
 	}
 
-	pl.chanPlay <- struct{}{}
-
 	pl.playing = true
 
+	pl.wake()
+
 	log.Printf("playlist | id %d | play | songid %d | time %d", pl.Id, pl.curr.Id, pl.time)
 
+	pl.broadcast(EventPlay)
+
 	return nil
 }
 
 func (pl *Playlist) Pause() error {
-	pl.RLock()
-	defer pl.RUnlock()
+	pl.Lock()
+	defer pl.Unlock()
 
 	if !pl.processing {
 		return ErrNotProcessed
@@ -226,159 +501,542 @@ func (pl *Playlist) Pause() error {
 		return ErrAlreadyPaused
 	}
 
-	pl.chanPaus <- struct{}{}
-
 	pl.playing = false
 
+	pl.wake()
+
 	log.Printf("playlist | id %d | pause | songid %d | time %d", pl.Id, pl.curr.Id, pl.time)
 
+	pl.broadcast(EventPause)
+
 	return nil
 }
 
 func (pl *Playlist) Next() error {
-	pl.RLock()
-	defer pl.RUnlock()
+	pl.Lock()
+	defer pl.Unlock()
+
+	if pl.head == nil {
+		return ErrNoSongs
+	}
 
 	if !pl.processing {
 		return ErrNotProcessed
 	}
 
-	if pl.curr.next == nil {
-		return ErrSwitchLast
+	switch {
+	case len(pl.queue) > 0:
+		pl.switchQueued()
+	case pl.nextSong(pl.curr) != nil:
+		pl.switchNext()
+	case pl.repeat != RepeatOff:
+		pl.switchFirst()
+	default:
+		return ErrEndOfPlaylist
 	}
 
-	pl.switchNext()
+	pl.record()
+
+	pl.wake()
 
-	pl.chanNext <- struct{}{}
+	pl.broadcast(EventNext)
 
 	return nil
 }
 
 func (pl *Playlist) Prev() error {
-	pl.RLock()
-	defer pl.RUnlock()
+	pl.Lock()
+	defer pl.Unlock()
+
+	if pl.head == nil {
+		return ErrNoSongs
+	}
 
 	if !pl.processing {
 		return ErrNotProcessed
 	}
 
-	if pl.curr.prev == nil {
-		return ErrSwitchFirst
+	switch {
+	case pl.prevSong(pl.curr) != nil:
+		pl.curr = pl.prevSong(pl.curr)
+	case pl.repeat != RepeatOff:
+		pl.curr = pl.lastSong()
+	default:
+		return ErrStartOfPlaylist
 	}
 
-	pl.curr = pl.curr.prev
 	pl.time = 0
 
 	log.Printf("playlist | id %d | prev | songid %d | duration %d", pl.Id, pl.curr.Id, pl.curr.Duration)
 
-	pl.chanPrev <- struct{}{}
+	pl.record()
+
+	pl.wake()
+
+	pl.broadcast(EventPrev)
 
 	return nil
 }
 
-func (pl *Playlist) Stop() error {
-	pl.RLock()
-	defer pl.RUnlock()
+func (pl *Playlist) PlaySong(id uint) error {
+	pl.Lock()
+	defer pl.Unlock()
 
 	if !pl.processing {
-		return ErrAlreadyStopped
+		return ErrNotProcessed
 	}
 
-	pl.chanStop <- struct{}{}
+	song := pl.findSong(id)
+	if song == nil {
+		return ErrSongNotIn
+	}
 
-	pl.processing = false
+	pl.jump(song)
 
-	log.Printf("playlist | id %d | stop", pl.Id)
+	pl.countPlay()
 
 	return nil
 }
 
-func (pl *Playlist) AddSong(id uint, name string, duration uint) error {
+func (pl *Playlist) Restart() (Song, error) {
 	pl.Lock()
 	defer pl.Unlock()
 
-	if pl.findSong(id) != nil {
-		return ErrSongIdTaken
+	if pl.head == nil {
+		return Song{}, ErrNoSongs
 	}
 
-	song := &Song{
-		Id:       id,
-		Name:     name,
-		Duration: duration,
-	}
+	pl.curr = pl.head
+	pl.time = 0
 
-	if pl.head == nil {
-		pl.head = song
-		pl.curr = song
-	} else {
-		song.prev = pl.tail
-		pl.tail.next = song
-	}
+	log.Printf("playlist | id %d | restart | songid %d | duration %d", pl.Id, pl.curr.Id, pl.curr.Duration)
 
-	pl.tail = song
+	if pl.processing {
+		pl.record()
 
-	log.Printf("playlist | id %d | add song | songid %d | duration %d", pl.Id, song.Id, song.Duration)
+		pl.wake()
 
-	return nil
+		pl.broadcast(EventRestart)
+	}
+
+	return *pl.curr, nil
 }
 
-func (pl *Playlist) Remove(id uint) error {
+func (pl *Playlist) SeekIndex(i int) error {
 	pl.Lock()
 	defer pl.Unlock()
 
-	if pl.head == nil {
-		return ErrRemoveFromEmpty
+	if !pl.processing {
+		return ErrNotProcessed
 	}
 
-	if pl.playing && pl.curr.Id == id {
-		return ErrRemovePlaying
+	var songs []*Song
+
+	for s := pl.head; s != nil; s = s.next {
+		songs = append(songs, s)
 	}
 
-	var song *Song
+	if i < 0 {
+		i += len(songs)
+	}
 
-	if pl.curr.Id == id {
-		song = pl.curr
-		pl.time = 0
-	} else {
-		song = pl.findSong(id)
+	if i < 0 || i >= len(songs) {
+		return ErrIndexOutOfRange
+	}
+
+	pl.jump(songs[i])
+
+	return nil
+}
+
+func (pl *Playlist) jump(song *Song) {
+	pl.curr = song
+	pl.time = 0
+
+	log.Printf("playlist | id %d | jump | songid %d | duration %d", pl.Id, pl.curr.Id, pl.curr.Duration)
+
+	pl.record()
+
+	pl.wake()
+
+	pl.broadcast(EventJump)
+}
+
+func (pl *Playlist) Stop() error {
+	pl.Lock()
+	defer pl.Unlock()
+
+	if !pl.processing {
+		return ErrAlreadyStopped
+	}
+
+	pl.processing = false
+	pl.queue = nil
+
+	pl.history.reset()
+
+	pl.wake()
+
+	log.Printf("playlist | id %d | stop", pl.Id)
+
+	return nil
+}
+
+func (pl *Playlist) AddSong(id uint, name string, duration uint) error {
+	pl.Lock()
+	defer pl.Unlock()
+
+	if pl.findSong(id) != nil {
+		return ErrSongIdTaken
+	}
+
+	song := &Song{
+		Id:        id,
+		Name:      name,
+		Duration:  duration,
+		CreatedAt: now(),
+		UpdatedAt: now(),
+	}
+
+	pl.updated = now()
+
+	if pl.head == nil {
+		pl.head = song
+		pl.curr = song
+	} else {
+		song.prev = pl.tail
+		pl.tail.next = song
+	}
+
+	pl.tail = song
+
+	if pl.shuffle {
+		pl.insertOrder(song)
+	}
+
+	log.Printf("playlist | id %d | add song | songid %d | duration %d", pl.Id, song.Id, song.Duration)
+
+	return nil
+}
+
+func (pl *Playlist) InsertSong(id uint, name string, duration uint, position int) error {
+	pl.Lock()
+	defer pl.Unlock()
+
+	if pl.findSong(id) != nil {
+		return ErrSongIdTaken
+	}
+
+	song := &Song{
+		Id:        id,
+		Name:      name,
+		Duration:  duration,
+		CreatedAt: now(),
+		UpdatedAt: now(),
+	}
+
+	pl.updated = now()
+
+	if pl.head == nil {
+		pl.curr = song
+	}
+
+	pl.insertAt(song, position)
+
+	if pl.shuffle {
+		pl.insertOrder(song)
+	}
+
+	log.Printf("playlist | id %d | insert song | songid %d | position %d", pl.Id, song.Id, position)
+
+	return nil
+}
+
+func (pl *Playlist) Remove(id uint) error {
+	pl.Lock()
+	defer pl.Unlock()
+
+	if pl.head == nil {
+		return ErrRemoveFromEmpty
+	}
+
+	if pl.playing && pl.curr.Id == id {
+		return ErrRemovePlaying
+	}
+
+	var song *Song
+
+	if pl.curr.Id == id {
+		song = pl.curr
+		pl.time = 0
+	} else {
+		song = pl.findSong(id)
 
 		if song == nil {
 			return ErrRemoveNotIn
 		}
 	}
 
-	if song == pl.head {
-		if song.next == nil {
-			pl.head = nil
-			pl.tail = nil
-			pl.curr = nil
-		} else {
-			pl.head = song.next
-			pl.curr = pl.head
-			song.next.prev = nil
-		}
-	} else if song == pl.tail {
-		pl.tail = song.prev
-		pl.curr = pl.tail
-		song.prev.next = nil
-	} else {
-		song.prev.next = song.next
-		song.next.prev = song.prev
+	if song == pl.curr {
 		pl.curr = song.next
+
+		if pl.curr == nil {
+			pl.curr = song.prev
+		}
 	}
 
-	song.next = nil
-	song.prev = nil
+	pl.unlink(song)
+	pl.unqueue(song)
+
+	if pl.shuffle {
+		pl.removeOrder(song)
+	}
+
+	pl.updated = now()
+
+	pl.wake()
 
 	log.Printf("playlist | id %d | remove | songid %d", pl.Id, song.Id)
 
 	return nil
 }
 
-func (pl *Playlist) SetTime(time uint) error {
+func (pl *Playlist) Restore(id uint, time uint, playing bool) error {
+	pl.Lock()
+	defer pl.Unlock()
+
+	if pl.processing {
+		return ErrAlreadyProcessing
+	}
+
+	song := pl.findSong(id)
+	if song == nil {
+		return ErrSongNotIn
+	}
+
+	if time > song.Duration {
+		time = song.Duration
+	}
+
+	pl.curr = song
+	pl.time = time
+	pl.playing = playing
+
+	log.Printf("playlist | id %d | restore | songid %d | time %d | playing %t", pl.Id, song.Id, pl.time, pl.playing)
+
+	return nil
+}
+
+func (pl *Playlist) Sync(id uint, time uint) error {
+	pl.Lock()
+	defer pl.Unlock()
+
+	if !pl.processing {
+		return ErrNotProcessed
+	}
+
+	song := pl.findSong(id)
+	if song == nil {
+		return ErrSongNotIn
+	}
+
+	if time > song.Duration {
+		time = song.Duration
+	}
+
+	pl.jump(song)
+
+	pl.time = time
+
+	return nil
+}
+
+func (pl *Playlist) SetName(name string) {
+	pl.Lock()
+	defer pl.Unlock()
+
+	pl.Name = name
+	pl.updated = now()
+}
+
+func (pl *Playlist) Clear() {
+	pl.Lock()
+	defer pl.Unlock()
+
+	pl.head = nil
+	pl.tail = nil
+	pl.curr = nil
+	pl.order = nil
+	pl.queue = nil
+	pl.time = 0
+	pl.updated = now()
+
+	log.Printf("playlist | id %d | clear", pl.Id)
+}
+
+func (pl *Playlist) MoveSong(id uint, position int) error {
+	pl.Lock()
+	defer pl.Unlock()
+
+	song := pl.findSong(id)
+	if song == nil {
+		return ErrSongNotIn
+	}
+
+	pl.unlink(song)
+	pl.insertAt(song, position)
+
+	pl.updated = now()
+
+	log.Printf("playlist | id %d | move | songid %d | position %d", pl.Id, song.Id, position)
+
+	return nil
+}
+
+func (pl *Playlist) SwapSongs(a uint, b uint) error {
+	if a == b {
+		return ErrSwapSame
+	}
+
+	pl.Lock()
+	defer pl.Unlock()
+
+	first, second := pl.findSong(a), pl.findSong(b)
+	if first == nil || second == nil {
+		return ErrSongNotIn
+	}
+
+	i, j := pl.indexOf(first), pl.indexOf(second)
+	if i > j {
+		first, second, i, j = second, first, j, i
+	}
+
+	pl.unlink(second)
+	pl.insertAt(second, i)
+	pl.unlink(first)
+	pl.insertAt(first, j)
+
+	pl.updated = now()
+
+	log.Printf("playlist | id %d | swap | songid %d | songid %d", pl.Id, a, b)
+
+	return nil
+}
+
+func (pl *Playlist) indexOf(song *Song) int {
+	index := 0
+
+	for s := pl.head; s != nil && s != song; s = s.next {
+		index++
+	}
+
+	return index
+}
+
+func (pl *Playlist) SetShuffle(shuffle bool) error {
+	pl.Lock()
+	defer pl.Unlock()
+
+	pl.shuffle = shuffle
+	pl.order = nil
+
+	if shuffle {
+		pl.shuffleOrder()
+	}
+
+	log.Printf("playlist | id %d | set shuffle | shuffle %t", pl.Id, pl.shuffle)
+
+	return nil
+}
+
+func (pl *Playlist) SetRepeat(repeat Repeat) error {
+	switch repeat {
+	case RepeatOff, RepeatOne, RepeatAll:
+	default:
+		return ErrInvalidRepeat
+	}
+
+	pl.Lock()
+	defer pl.Unlock()
+
+	pl.repeat = repeat
+
+	log.Printf("playlist | id %d | set repeat | repeat %s", pl.Id, pl.repeat)
+
+	return nil
+}
+
+func (pl *Playlist) SetSpeed(speed float64) error {
+	if speed < MinSpeed || speed > MaxSpeed {
+		return ErrInvalidSpeed
+	}
+
+	pl.Lock()
+	defer pl.Unlock()
+
+	pl.speed = speed
+
+	pl.wake()
+
+	log.Printf("playlist | id %d | set speed | speed %g", pl.Id, pl.speed)
+
+	return nil
+}
+
+func (pl *Playlist) SetTickInterval(step time.Duration) {
+	pl.Lock()
+	defer pl.Unlock()
+
+	pl.step = step
+}
+
+func (pl *Playlist) SetVolume(volume uint) {
+	pl.Lock()
+	defer pl.Unlock()
+
+	pl.volume = volume
+
+	log.Printf("playlist | id %d | set volume | volume %d", pl.Id, pl.volume)
+}
+
+func (pl *Playlist) Volume() uint {
+	pl.RLock()
+	defer pl.RUnlock()
+
+	return pl.volume
+}
+
+func (pl *Playlist) SetVersion(version uint) {
+	pl.Lock()
+	defer pl.Unlock()
+
+	pl.version = version
+}
+
+func (pl *Playlist) BumpVersion() uint {
+	pl.Lock()
+	defer pl.Unlock()
+
+	pl.version++
+
+	return pl.version
+}
+
+func (pl *Playlist) Version() uint {
 	pl.RLock()
 	defer pl.RUnlock()
 
+	return pl.version
+}
+
+func (pl *Playlist) SetTime(time uint) error {
+	pl.Lock()
+	defer pl.Unlock()
+
+	if pl.curr == nil {
+		return ErrNoSongs
+	}
+
 	if time > pl.curr.Duration {
 		return ErrLargerTime
 	}
@@ -390,9 +1048,20 @@ func (pl *Playlist) SetTime(time uint) error {
 	return nil
 }
 
+func (pl *Playlist) Elapsed() (uint, uint) {
+	pl.RLock()
+	defer pl.RUnlock()
+
+	if !pl.processing || pl.curr == nil {
+		return 0, 0
+	}
+
+	return pl.time, pl.curr.Duration
+}
+
 func (pl *Playlist) Status() Status {
-	pl.Lock()
-	defer pl.Unlock()
+	pl.RLock()
+	defer pl.RUnlock()
 
 	var id uint
 	var name string
@@ -415,24 +1084,103 @@ func (pl *Playlist) Status() Status {
 		CurrentId:   id,
 		CurrentName: name,
 		Duration:    duration,
+		Shuffle:     pl.shuffle,
+		Repeat:      pl.repeat,
+		Speed:       pl.speed,
+		Queue:       pl.queueIds(),
+		CreatedAt:   pl.created,
+		UpdatedAt:   pl.updated,
+	}
+}
+
+func (pl *Playlist) Current() *Current {
+	pl.RLock()
+	defer pl.RUnlock()
+
+	if !pl.processing || pl.curr == nil {
+		return nil
+	}
+
+	index := 0
+
+	for s := pl.head; s != pl.curr; s = s.next {
+		index++
+	}
+
+	return &Current{
+		Id:    pl.curr.Id,
+		Name:  pl.curr.Name,
+		Index: index,
+	}
+}
+
+func (pl *Playlist) TotalDuration() uint64 {
+	pl.RLock()
+	defer pl.RUnlock()
+
+	return pl.totalDuration()
+}
+
+func (pl *Playlist) Remaining() uint64 {
+	pl.RLock()
+	defer pl.RUnlock()
+
+	if !pl.processing || pl.curr == nil {
+		return pl.totalDuration()
+	}
+
+	var remaining uint64
+
+	if pl.time < pl.curr.Duration {
+		remaining = uint64(pl.curr.Duration - pl.time)
+	}
+
+	for s := pl.nextSong(pl.curr); s != nil; s = pl.nextSong(s) {
+		remaining += uint64(s.Duration)
+	}
+
+	return remaining
+}
+
+func (pl *Playlist) GetSong(id uint) (Song, error) {
+	pl.RLock()
+	defer pl.RUnlock()
+
+	song := pl.findSong(id)
+	if song == nil {
+		return Song{}, ErrSongNotIn
 	}
+
+	return *song, nil
 }
 
-func (pl *Playlist) GetSong(id uint) (*Song, error) {
+func (pl *Playlist) EditSong(id uint, name string, duration uint) error {
 	pl.Lock()
 	defer pl.Unlock()
 
 	song := pl.findSong(id)
 	if song == nil {
-		return nil, ErrSongNotIn
+		return ErrSongNotIn
 	}
 
-	return song, nil
+	song.Name = name
+	song.Duration = duration
+	song.UpdatedAt = now()
+
+	pl.updated = now()
+
+	if pl.curr == song {
+		pl.time = 0
+	}
+
+	log.Printf("playlist | id %d | edit song | songid %d | duration %d", pl.Id, song.Id, song.Duration)
+
+	return nil
 }
 
 func (pl *Playlist) GetSongsList() []Song {
-	pl.Lock()
-	defer pl.Unlock()
+	pl.RLock()
+	defer pl.RUnlock()
 
 	var songs []Song
 
@@ -443,6 +1191,61 @@ func (pl *Playlist) GetSongsList() []Song {
 	return songs
 }
 
+func (pl *Playlist) SongCount() int {
+	pl.RLock()
+	defer pl.RUnlock()
+
+	count := 0
+
+	for s := pl.head; s != nil; s = s.next {
+		count++
+	}
+
+	return count
+}
+
+func (pl *Playlist) SongsAfter(id uint, limit int, match func(Song) bool) ([]Song, bool, error) {
+	pl.RLock()
+	defer pl.RUnlock()
+
+	s := pl.head
+
+	if id != 0 {
+		song := pl.findSong(id)
+		if song == nil {
+			return nil, false, ErrSongNotIn
+		}
+
+		s = song.next
+	}
+
+	var songs []Song
+
+	for ; s != nil; s = s.next {
+		if match != nil && !match(*s) {
+			continue
+		}
+
+		if len(songs) == limit {
+			return songs, true, nil
+		}
+
+		songs = append(songs, *s)
+	}
+
+	return songs, false, nil
+}
+
+func (pl *Playlist) totalDuration() uint64 {
+	var total uint64
+
+	for s := pl.head; s != nil; s = s.next {
+		total += uint64(s.Duration)
+	}
+
+	return total
+}
+
 func (pl *Playlist) findSong(id uint) *Song {
 	var song *Song
 
@@ -456,3 +1259,140 @@ func (pl *Playlist) findSong(id uint) *Song {
 
 	return song
 }
+
+func (pl *Playlist) unlink(song *Song) {
+	if song.prev != nil {
+		song.prev.next = song.next
+	} else {
+		pl.head = song.next
+	}
+
+	if song.next != nil {
+		song.next.prev = song.prev
+	} else {
+		pl.tail = song.prev
+	}
+
+	song.prev = nil
+	song.next = nil
+}
+
+func (pl *Playlist) insertAt(song *Song, position int) {
+	at := pl.head
+
+	for i := 0; i < position && at != nil; i++ {
+		at = at.next
+	}
+
+	if at == nil {
+		song.prev = pl.tail
+
+		if pl.tail != nil {
+			pl.tail.next = song
+		} else {
+			pl.head = song
+		}
+
+		pl.tail = song
+
+		return
+	}
+
+	song.next = at
+	song.prev = at.prev
+
+	if at.prev != nil {
+		at.prev.next = song
+	} else {
+		pl.head = song
+	}
+
+	at.prev = song
+}
+
+func (pl *Playlist) firstSong() *Song {
+	if pl.shuffle && len(pl.order) > 0 {
+		return pl.order[0]
+	}
+
+	return pl.head
+}
+
+func (pl *Playlist) lastSong() *Song {
+	if pl.shuffle && len(pl.order) > 0 {
+		return pl.order[len(pl.order)-1]
+	}
+
+	return pl.tail
+}
+
+func (pl *Playlist) nextSong(song *Song) *Song {
+	if !pl.shuffle {
+		return song.next
+	}
+
+	i := pl.orderIndex(song)
+	if i < 0 || i+1 >= len(pl.order) {
+		return nil
+	}
+
+	return pl.order[i+1]
+}
+
+func (pl *Playlist) prevSong(song *Song) *Song {
+	if !pl.shuffle {
+		return song.prev
+	}
+
+	i := pl.orderIndex(song)
+	if i < 1 {
+		return nil
+	}
+
+	return pl.order[i-1]
+}
+
+func (pl *Playlist) orderIndex(song *Song) int {
+	for i, s := range pl.order {
+		if s == song {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (pl *Playlist) shuffleOrder() {
+	var rest []*Song
+
+	for s := pl.head; s != nil; s = s.next {
+		if s != pl.curr {
+			rest = append(rest, s)
+		}
+	}
+
+	pl.rnd.Shuffle(len(rest), func(i, j int) {
+		rest[i], rest[j] = rest[j], rest[i]
+	})
+
+	if pl.curr != nil {
+		pl.order = append([]*Song{pl.curr}, rest...)
+	} else {
+		pl.order = rest
+	}
+}
+
+func (pl *Playlist) insertOrder(song *Song) {
+	from := pl.orderIndex(pl.curr) + 1
+	i := from + pl.rnd.Intn(len(pl.order)-from+1)
+
+	pl.order = append(pl.order, nil)
+	copy(pl.order[i+1:], pl.order[i:])
+	pl.order[i] = song
+}
+
+func (pl *Playlist) removeOrder(song *Song) {
+	if i := pl.orderIndex(song); i >= 0 {
+		pl.order = append(pl.order[:i], pl.order[i+1:]...)
+	}
+}
@@ -0,0 +1,291 @@
+package playlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+
+	os.Exit(m.Run())
+}
+
+func newTestPlaylist(t *testing.T, songs int) *Playlist {
+	t.Helper()
+
+	pl := New(1, "test")
+
+	for i := 1; i <= songs; i++ {
+		if err := pl.AddSong(uint(i), "song", 3); err != nil {
+			t.Fatalf("add song %d: %v", i, err)
+		}
+	}
+
+	return pl
+}
+
+func launchTestPlaylist(t *testing.T, pl *Playlist) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		pl.Process(ctx)
+		close(done)
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	for !pl.IsProcessing() {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestConcurrentControl(t *testing.T) {
+	pl := newTestPlaylist(t, 5)
+	pl.SetTickInterval(time.Millisecond)
+
+	if err := pl.SetRepeat(RepeatAll); err != nil {
+		t.Fatal(err)
+	}
+
+	launchTestPlaylist(t, pl)
+
+	if err := pl.Play(); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+
+	workers := []func(){
+		func() { _ = pl.Next() },
+		func() { _ = pl.Prev() },
+		func() { _ = pl.Pause() },
+		func() { _ = pl.Play() },
+		func() { _ = pl.SetTime(1) },
+		func() { _ = pl.Status() },
+		func() { _ = pl.Current() },
+		func() { pl.Elapsed() },
+	}
+
+	for _, work := range workers {
+		wg.Add(1)
+
+		go func(work func()) {
+			defer wg.Done()
+
+			for i := 0; i < 200; i++ {
+				work()
+			}
+		}(work)
+	}
+
+	wg.Wait()
+
+	st := pl.Status()
+	if !st.Processing {
+		t.Fatal("playlist stopped processing during concurrent control")
+	}
+
+	if st.CurrentId == 0 {
+		t.Fatal("playlist lost its current song during concurrent control")
+	}
+}
+
+func TestEmptyPlaylist(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(pl *Playlist) error
+	}{
+		{"play", func(pl *Playlist) error { return pl.Play() }},
+		{"next", func(pl *Playlist) error { return pl.Next() }},
+		{"prev", func(pl *Playlist) error { return pl.Prev() }},
+		{"restart", func(pl *Playlist) error { _, err := pl.Restart(); return err }},
+		{"set time", func(pl *Playlist) error { return pl.SetTime(1) }},
+	}
+
+	for _, tt := range tests {
+		for _, processing := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s processing %t", tt.name, processing), func(t *testing.T) {
+				pl := newTestPlaylist(t, 0)
+				pl.processing = processing
+
+				if err := tt.call(pl); !errors.Is(err, ErrNoSongs) {
+					t.Fatalf("err %v, want %v", err, ErrNoSongs)
+				}
+			})
+		}
+	}
+}
+
+func TestBoundaries(t *testing.T) {
+	tests := []struct {
+		name   string
+		repeat Repeat
+		index  int
+		call   func(pl *Playlist) error
+		err    error
+		want   uint
+	}{
+		{"next at end repeat off", RepeatOff, -1, (*Playlist).Next, ErrEndOfPlaylist, 3},
+		{"next at end repeat all", RepeatAll, -1, (*Playlist).Next, nil, 1},
+		{"prev at start repeat off", RepeatOff, 0, (*Playlist).Prev, ErrStartOfPlaylist, 1},
+		{"prev at start repeat all", RepeatAll, 0, (*Playlist).Prev, nil, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pl := newTestPlaylist(t, 3)
+			pl.processing = true
+			pl.curr = pl.head
+
+			if err := pl.SetRepeat(tt.repeat); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := pl.SeekIndex(tt.index); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := pl.SetTime(2); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := tt.call(pl); !errors.Is(err, tt.err) {
+				t.Fatalf("err %v, want %v", err, tt.err)
+			}
+
+			st := pl.Status()
+
+			if st.CurrentId != tt.want {
+				t.Fatalf("current song %d, want %d", st.CurrentId, tt.want)
+			}
+
+			if tt.err != nil && st.Time != 2 {
+				t.Fatalf("time %d after failed switch, want 2", st.Time)
+			}
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		seconds uint
+		want    string
+	}{
+		{0, "0:00"},
+		{5, "0:05"},
+		{225, "3:45"},
+		{3599, "59:59"},
+		{3600, "1:00:00"},
+		{3723, "1:02:03"},
+		{86400, "24:00:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := FormatDuration(tt.seconds); got != tt.want {
+				t.Fatalf("format %d: %q, want %q", tt.seconds, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistoryResetOnEnd(t *testing.T) {
+	tests := []struct {
+		name   string
+		finish func(t *testing.T, pl *Playlist, cancel context.CancelFunc)
+	}{
+		{"end of playlist", func(t *testing.T, pl *Playlist, cancel context.CancelFunc) {
+			for _, step := range []func() error{pl.Next, func() error { return pl.SetSpeed(MaxSpeed) }, func() error { return pl.SetTime(2) }, pl.Play} {
+				if err := step(); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}},
+		{"context cancelled", func(t *testing.T, pl *Playlist, cancel context.CancelFunc) {
+			cancel()
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pl := newTestPlaylist(t, 2)
+			pl.SetHistorySize(10)
+			pl.SetTickInterval(time.Millisecond)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			done := make(chan struct{})
+
+			go func() {
+				pl.Process(ctx)
+				close(done)
+			}()
+
+			for !pl.IsProcessing() {
+				time.Sleep(time.Millisecond)
+			}
+
+			if n := len(pl.History(10)); n == 0 {
+				t.Fatal("no history recorded on launch")
+			}
+
+			tt.finish(t, pl, cancel)
+
+			select {
+			case <-done:
+			case <-time.After(time.Second * 5):
+				t.Fatal("playlist still processing")
+			}
+
+			if entries := pl.History(10); len(entries) != 0 {
+				t.Fatalf("%d history entries after processing ended, want 0", len(entries))
+			}
+		})
+	}
+}
+
+func TestRestartShuffled(t *testing.T) {
+	pl := newTestPlaylist(t, 5)
+
+	launchTestPlaylist(t, pl)
+
+	for _, step := range []func() error{pl.Next, pl.Next, func() error { return pl.SetShuffle(true) }} {
+		if err := step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sn, err := pl.Restart()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sn.Id != 1 {
+		t.Fatalf("restart to song %d, want %d", sn.Id, 1)
+	}
+
+	st := pl.Status()
+
+	if st.CurrentId != 1 || !st.Shuffle {
+		t.Fatalf("current %d shuffle %t after restart, want %d and %t", st.CurrentId, st.Shuffle, 1, true)
+	}
+
+	if elapsed, _ := pl.Elapsed(); elapsed != 0 {
+		t.Fatalf("elapsed %d after restart, want 0", elapsed)
+	}
+}
@@ -0,0 +1,71 @@
+package playlist
+
+import (
+	"errors"
+	"log"
+)
+
+var ErrQueueFull = errors.New("play next queue is full")
+
+const MaxQueueSize = 100
+
+func (pl *Playlist) Enqueue(id uint) error {
+	pl.Lock()
+	defer pl.Unlock()
+
+	if !pl.processing {
+		return ErrNotProcessed
+	}
+
+	song := pl.findSong(id)
+	if song == nil {
+		return ErrSongNotIn
+	}
+
+	if len(pl.queue) >= MaxQueueSize {
+		return ErrQueueFull
+	}
+
+	pl.queue = append(pl.queue, song)
+
+	log.Printf("playlist | id %d | enqueue | songid %d | queued %d", pl.Id, song.Id, len(pl.queue))
+
+	return nil
+}
+
+func (pl *Playlist) Queue() []uint {
+	pl.RLock()
+	defer pl.RUnlock()
+
+	return pl.queueIds()
+}
+
+func (pl *Playlist) queueIds() []uint {
+	ids := make([]uint, 0, len(pl.queue))
+
+	for _, song := range pl.queue {
+		ids = append(ids, song.Id)
+	}
+
+	return ids
+}
+
+func (pl *Playlist) switchQueued() {
+	pl.curr = pl.queue[0]
+	pl.queue = pl.queue[1:]
+	pl.time = 0
+
+	log.Printf("playlist | id %d | queued | songid %d | duration %d", pl.Id, pl.curr.Id, pl.curr.Duration)
+}
+
+func (pl *Playlist) unqueue(song *Song) {
+	queue := pl.queue[:0]
+
+	for _, queued := range pl.queue {
+		if queued != song {
+			queue = append(queue, queued)
+		}
+	}
+
+	pl.queue = queue
+}
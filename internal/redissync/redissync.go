@@ -0,0 +1,71 @@
+package redissync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"gocloudcamp_test/internal/service"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type Syncer struct {
+	client *redis.Client
+	prefix string
+}
+
+func New(addr string, prefix string) *Syncer {
+	log.Printf("redis | sync | addr %s | prefix %s", addr, prefix)
+
+	return &Syncer{
+		client: redis.NewClient(&redis.Options{Addr: addr}),
+		prefix: prefix,
+	}
+}
+
+func (rs *Syncer) Publish(ctx context.Context, msg service.SyncMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return rs.client.Publish(ctx, fmt.Sprintf("%s:%d", rs.prefix, msg.PlaylistId), data).Err()
+}
+
+func (rs *Syncer) Subscribe(ctx context.Context, handler func(service.SyncMessage)) error {
+	ps := rs.client.PSubscribe(ctx, rs.prefix+":*")
+	defer ps.Close()
+
+	if _, err := ps.Receive(ctx); err != nil {
+		return err
+	}
+
+	ch := ps.Channel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case m, ok := <-ch:
+			if !ok {
+				return nil
+			}
+
+			var msg service.SyncMessage
+
+			if err := json.Unmarshal([]byte(m.Payload), &msg); err != nil {
+				log.Printf("redis | sync | invalid message | %v", err)
+
+				continue
+			}
+
+			handler(msg)
+		}
+	}
+}
+
+func (rs *Syncer) Close() error {
+	return rs.client.Close()
+}
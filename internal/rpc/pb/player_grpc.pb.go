@@ -0,0 +1,442 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: player.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Player_CreatePlaylist_FullMethodName = "/player.v1.Player/CreatePlaylist"
+	Player_GetPlaylist_FullMethodName    = "/player.v1.Player/GetPlaylist"
+	Player_ListPlaylists_FullMethodName  = "/player.v1.Player/ListPlaylists"
+	Player_AddSong_FullMethodName        = "/player.v1.Player/AddSong"
+	Player_Launch_FullMethodName         = "/player.v1.Player/Launch"
+	Player_Stop_FullMethodName           = "/player.v1.Player/Stop"
+	Player_Play_FullMethodName           = "/player.v1.Player/Play"
+	Player_Pause_FullMethodName          = "/player.v1.Player/Pause"
+	Player_Next_FullMethodName           = "/player.v1.Player/Next"
+	Player_Prev_FullMethodName           = "/player.v1.Player/Prev"
+)
+
+// PlayerClient is the client API for Player service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PlayerClient interface {
+	CreatePlaylist(ctx context.Context, in *CreatePlaylistRequest, opts ...grpc.CallOption) (*PlaylistReply, error)
+	GetPlaylist(ctx context.Context, in *PlaylistRequest, opts ...grpc.CallOption) (*PlaylistReply, error)
+	ListPlaylists(ctx context.Context, in *ListPlaylistsRequest, opts ...grpc.CallOption) (*ListPlaylistsReply, error)
+	AddSong(ctx context.Context, in *AddSongRequest, opts ...grpc.CallOption) (*PlaylistReply, error)
+	Launch(ctx context.Context, in *PlaylistRequest, opts ...grpc.CallOption) (*MessageReply, error)
+	Stop(ctx context.Context, in *PlaylistRequest, opts ...grpc.CallOption) (*MessageReply, error)
+	Play(ctx context.Context, in *PlaylistRequest, opts ...grpc.CallOption) (*MessageReply, error)
+	Pause(ctx context.Context, in *PlaylistRequest, opts ...grpc.CallOption) (*MessageReply, error)
+	Next(ctx context.Context, in *PlaylistRequest, opts ...grpc.CallOption) (*MessageReply, error)
+	Prev(ctx context.Context, in *PlaylistRequest, opts ...grpc.CallOption) (*MessageReply, error)
+}
+
+type playerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPlayerClient(cc grpc.ClientConnInterface) PlayerClient {
+	return &playerClient{cc}
+}
+
+func (c *playerClient) CreatePlaylist(ctx context.Context, in *CreatePlaylistRequest, opts ...grpc.CallOption) (*PlaylistReply, error) {
+	out := new(PlaylistReply)
+	err := c.cc.Invoke(ctx, Player_CreatePlaylist_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *playerClient) GetPlaylist(ctx context.Context, in *PlaylistRequest, opts ...grpc.CallOption) (*PlaylistReply, error) {
+	out := new(PlaylistReply)
+	err := c.cc.Invoke(ctx, Player_GetPlaylist_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *playerClient) ListPlaylists(ctx context.Context, in *ListPlaylistsRequest, opts ...grpc.CallOption) (*ListPlaylistsReply, error) {
+	out := new(ListPlaylistsReply)
+	err := c.cc.Invoke(ctx, Player_ListPlaylists_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *playerClient) AddSong(ctx context.Context, in *AddSongRequest, opts ...grpc.CallOption) (*PlaylistReply, error) {
+	out := new(PlaylistReply)
+	err := c.cc.Invoke(ctx, Player_AddSong_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *playerClient) Launch(ctx context.Context, in *PlaylistRequest, opts ...grpc.CallOption) (*MessageReply, error) {
+	out := new(MessageReply)
+	err := c.cc.Invoke(ctx, Player_Launch_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *playerClient) Stop(ctx context.Context, in *PlaylistRequest, opts ...grpc.CallOption) (*MessageReply, error) {
+	out := new(MessageReply)
+	err := c.cc.Invoke(ctx, Player_Stop_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *playerClient) Play(ctx context.Context, in *PlaylistRequest, opts ...grpc.CallOption) (*MessageReply, error) {
+	out := new(MessageReply)
+	err := c.cc.Invoke(ctx, Player_Play_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *playerClient) Pause(ctx context.Context, in *PlaylistRequest, opts ...grpc.CallOption) (*MessageReply, error) {
+	out := new(MessageReply)
+	err := c.cc.Invoke(ctx, Player_Pause_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *playerClient) Next(ctx context.Context, in *PlaylistRequest, opts ...grpc.CallOption) (*MessageReply, error) {
+	out := new(MessageReply)
+	err := c.cc.Invoke(ctx, Player_Next_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *playerClient) Prev(ctx context.Context, in *PlaylistRequest, opts ...grpc.CallOption) (*MessageReply, error) {
+	out := new(MessageReply)
+	err := c.cc.Invoke(ctx, Player_Prev_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PlayerServer is the server API for Player service.
+// All implementations must embed UnimplementedPlayerServer
+// for forward compatibility
+type PlayerServer interface {
+	CreatePlaylist(context.Context, *CreatePlaylistRequest) (*PlaylistReply, error)
+	GetPlaylist(context.Context, *PlaylistRequest) (*PlaylistReply, error)
+	ListPlaylists(context.Context, *ListPlaylistsRequest) (*ListPlaylistsReply, error)
+	AddSong(context.Context, *AddSongRequest) (*PlaylistReply, error)
+	Launch(context.Context, *PlaylistRequest) (*MessageReply, error)
+	Stop(context.Context, *PlaylistRequest) (*MessageReply, error)
+	Play(context.Context, *PlaylistRequest) (*MessageReply, error)
+	Pause(context.Context, *PlaylistRequest) (*MessageReply, error)
+	Next(context.Context, *PlaylistRequest) (*MessageReply, error)
+	Prev(context.Context, *PlaylistRequest) (*MessageReply, error)
+	mustEmbedUnimplementedPlayerServer()
+}
+
+// UnimplementedPlayerServer must be embedded to have forward compatible implementations.
+type UnimplementedPlayerServer struct {
+}
+
+func (UnimplementedPlayerServer) CreatePlaylist(context.Context, *CreatePlaylistRequest) (*PlaylistReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePlaylist not implemented")
+}
+func (UnimplementedPlayerServer) GetPlaylist(context.Context, *PlaylistRequest) (*PlaylistReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPlaylist not implemented")
+}
+func (UnimplementedPlayerServer) ListPlaylists(context.Context, *ListPlaylistsRequest) (*ListPlaylistsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPlaylists not implemented")
+}
+func (UnimplementedPlayerServer) AddSong(context.Context, *AddSongRequest) (*PlaylistReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddSong not implemented")
+}
+func (UnimplementedPlayerServer) Launch(context.Context, *PlaylistRequest) (*MessageReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Launch not implemented")
+}
+func (UnimplementedPlayerServer) Stop(context.Context, *PlaylistRequest) (*MessageReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stop not implemented")
+}
+func (UnimplementedPlayerServer) Play(context.Context, *PlaylistRequest) (*MessageReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Play not implemented")
+}
+func (UnimplementedPlayerServer) Pause(context.Context, *PlaylistRequest) (*MessageReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Pause not implemented")
+}
+func (UnimplementedPlayerServer) Next(context.Context, *PlaylistRequest) (*MessageReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Next not implemented")
+}
+func (UnimplementedPlayerServer) Prev(context.Context, *PlaylistRequest) (*MessageReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Prev not implemented")
+}
+func (UnimplementedPlayerServer) mustEmbedUnimplementedPlayerServer() {}
+
+// UnsafePlayerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PlayerServer will
+// result in compilation errors.
+type UnsafePlayerServer interface {
+	mustEmbedUnimplementedPlayerServer()
+}
+
+func RegisterPlayerServer(s grpc.ServiceRegistrar, srv PlayerServer) {
+	s.RegisterService(&Player_ServiceDesc, srv)
+}
+
+func _Player_CreatePlaylist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePlaylistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlayerServer).CreatePlaylist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Player_CreatePlaylist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlayerServer).CreatePlaylist(ctx, req.(*CreatePlaylistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Player_GetPlaylist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlaylistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlayerServer).GetPlaylist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Player_GetPlaylist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlayerServer).GetPlaylist(ctx, req.(*PlaylistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Player_ListPlaylists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPlaylistsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlayerServer).ListPlaylists(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Player_ListPlaylists_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlayerServer).ListPlaylists(ctx, req.(*ListPlaylistsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Player_AddSong_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddSongRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlayerServer).AddSong(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Player_AddSong_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlayerServer).AddSong(ctx, req.(*AddSongRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Player_Launch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlaylistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlayerServer).Launch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Player_Launch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlayerServer).Launch(ctx, req.(*PlaylistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Player_Stop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlaylistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlayerServer).Stop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Player_Stop_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlayerServer).Stop(ctx, req.(*PlaylistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Player_Play_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlaylistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlayerServer).Play(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Player_Play_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlayerServer).Play(ctx, req.(*PlaylistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Player_Pause_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlaylistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlayerServer).Pause(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Player_Pause_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlayerServer).Pause(ctx, req.(*PlaylistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Player_Next_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlaylistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlayerServer).Next(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Player_Next_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlayerServer).Next(ctx, req.(*PlaylistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Player_Prev_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlaylistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlayerServer).Prev(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Player_Prev_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlayerServer).Prev(ctx, req.(*PlaylistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Player_ServiceDesc is the grpc.ServiceDesc for Player service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Player_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "player.v1.Player",
+	HandlerType: (*PlayerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreatePlaylist",
+			Handler:    _Player_CreatePlaylist_Handler,
+		},
+		{
+			MethodName: "GetPlaylist",
+			Handler:    _Player_GetPlaylist_Handler,
+		},
+		{
+			MethodName: "ListPlaylists",
+			Handler:    _Player_ListPlaylists_Handler,
+		},
+		{
+			MethodName: "AddSong",
+			Handler:    _Player_AddSong_Handler,
+		},
+		{
+			MethodName: "Launch",
+			Handler:    _Player_Launch_Handler,
+		},
+		{
+			MethodName: "Stop",
+			Handler:    _Player_Stop_Handler,
+		},
+		{
+			MethodName: "Play",
+			Handler:    _Player_Play_Handler,
+		},
+		{
+			MethodName: "Pause",
+			Handler:    _Player_Pause_Handler,
+		},
+		{
+			MethodName: "Next",
+			Handler:    _Player_Next_Handler,
+		},
+		{
+			MethodName: "Prev",
+			Handler:    _Player_Prev_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "player.proto",
+}
@@ -0,0 +1,376 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"strings"
+
+	"gocloudcamp_test/internal/auth"
+	"gocloudcamp_test/internal/database"
+	"gocloudcamp_test/internal/playlist"
+	"gocloudcamp_test/internal/rpc/pb"
+	"gocloudcamp_test/internal/service"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrForbidden       = errors.New("playlist belongs to another user")
+	ErrNoSongsProvided = errors.New("no songs provided")
+	ErrInvalidLimit    = errors.New("limit must be a positive number")
+	ErrInvalidOffset   = errors.New("offset must not be negative")
+)
+
+const defaultLimit = 50
+
+type Server struct {
+	pb.UnimplementedPlayerServer
+
+	ctx     context.Context
+	addr    string
+	service *service.Service
+	server  *grpc.Server
+}
+
+func New(ctx context.Context, addr string, s *service.Service) *Server {
+	server := &Server{
+		ctx:     ctx,
+		addr:    addr,
+		service: s,
+	}
+
+	server.server = grpc.NewServer(grpc.ChainUnaryInterceptor(server.authenticate, server.mapErrors))
+
+	pb.RegisterPlayerServer(server.server, server)
+
+	return server
+}
+
+func (s *Server) Run() {
+	log.Printf("grpc | server starting | %s", s.addr)
+
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		log.Printf("grpc | error | %v", err)
+
+		return
+	}
+
+	if err := s.server.Serve(lis); err != nil && err != grpc.ErrServerStopped {
+		log.Printf("grpc | error | %v", err)
+	}
+}
+
+func (s *Server) GracefulStop() {
+	log.Print("grpc | server shutting down")
+
+	s.server.GracefulStop()
+
+	log.Print("grpc | server shut down")
+}
+
+func (s *Server) authenticate(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	config := s.service.Config()
+	if !config.AuthEnabled {
+		return handler(ctx, req)
+	}
+
+	var token string
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			token = strings.TrimPrefix(values[0], "Bearer ")
+		}
+	}
+
+	claims, err := auth.Parse([]byte(config.AuthSecret), token)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(auth.WithClaims(ctx, claims), req)
+}
+
+func (s *Server) mapErrors(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	if err == nil {
+		return resp, nil
+	}
+
+	code := errorCode(err)
+	if code == codes.Internal {
+		s.service.LogError("grpc "+info.FullMethod, err)
+	}
+
+	return nil, status.Error(code, err.Error())
+}
+
+func errorCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, auth.ErrMissingToken), errors.Is(err, auth.ErrInvalidToken):
+		return codes.Unauthenticated
+	case errors.Is(err, ErrForbidden):
+		return codes.PermissionDenied
+	case errors.Is(err, service.ErrPlaylistNotFound), errors.Is(err, playlist.ErrSongNotIn):
+		return codes.NotFound
+	case errors.Is(err, service.ErrDuplicateName):
+		return codes.AlreadyExists
+	case errors.Is(err, service.ErrUnavailable), errors.Is(err, service.ErrShuttingDown):
+		return codes.Unavailable
+	case errors.Is(err, service.ErrLaunchLimit):
+		return codes.ResourceExhausted
+	case errors.Is(err, ErrNoSongsProvided),
+		errors.Is(err, ErrInvalidLimit),
+		errors.Is(err, ErrInvalidOffset),
+		errors.Is(err, service.ErrInvalidName),
+		errors.Is(err, service.ErrInvalidDuration),
+		errors.Is(err, service.ErrNoSongs):
+		return codes.InvalidArgument
+	case errors.Is(err, service.ErrAlreadyLaunched),
+		errors.Is(err, service.ErrEndOfPlaylist),
+		errors.Is(err, service.ErrStartOfPlaylist),
+		errors.Is(err, playlist.ErrNotProcessed),
+		errors.Is(err, playlist.ErrAlreadyStopped),
+		errors.Is(err, playlist.ErrAlreadyPlaying),
+		errors.Is(err, playlist.ErrAlreadyPaused):
+		return codes.FailedPrecondition
+	}
+
+	return codes.Internal
+}
+
+func ownerFilter(ctx context.Context) (string, bool) {
+	claims, ok := auth.FromContext(ctx)
+	if !ok || claims.Admin {
+		return "", false
+	}
+
+	return claims.UserId, true
+}
+
+func (s *Server) playlist(ctx context.Context, id uint32) (*playlist.Playlist, error) {
+	pl, err := s.service.GetPlaylist(uint(id))
+	if err != nil {
+		return nil, err
+	}
+
+	if userId, restricted := ownerFilter(ctx); restricted && pl.Owner != userId {
+		return nil, ErrForbidden
+	}
+
+	return pl, nil
+}
+
+func songsInput(input []*pb.SongInput) []database.Song {
+	var dbsns []database.Song
+
+	for _, sn := range input {
+		dbsns = append(dbsns, database.Song{
+			Name:     sn.GetName(),
+			Duration: database.Duration(sn.GetDuration()),
+		})
+	}
+
+	return dbsns
+}
+
+func newPlaylist(pl *playlist.Playlist) *pb.Playlist {
+	st := pl.Status()
+
+	data := &pb.Playlist{
+		Id:            uint32(st.Id),
+		Name:          st.Name,
+		Processing:    st.Processing,
+		Playing:       st.Playing,
+		Time:          uint32(st.Time),
+		CurrentId:     uint32(st.CurrentId),
+		CurrentName:   st.CurrentName,
+		Shuffle:       st.Shuffle,
+		Repeat:        string(st.Repeat),
+		TotalDuration: pl.TotalDuration(),
+	}
+
+	for _, sn := range pl.GetSongsList() {
+		data.Songs = append(data.Songs, &pb.Song{
+			Id:        uint32(sn.Id),
+			Name:      sn.Name,
+			Duration:  uint32(sn.Duration),
+			PlayCount: uint32(sn.PlayCount),
+		})
+	}
+
+	return data
+}
+
+func (s *Server) CreatePlaylist(ctx context.Context, req *pb.CreatePlaylistRequest) (*pb.PlaylistReply, error) {
+	dbsns := songsInput(req.GetSongs())
+
+	if err := service.ValidateSongs(dbsns); err != nil {
+		return nil, err
+	}
+
+	var dbpl database.Playlist
+	dbpl.Name = req.GetName()
+
+	if err := s.service.CreatePlaylistWithSongs(ctx, &dbpl, dbsns); err != nil {
+		return nil, err
+	}
+
+	pl, err := s.service.GetPlaylist(dbpl.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.PlaylistReply{Playlist: newPlaylist(pl)}, nil
+}
+
+func (s *Server) GetPlaylist(ctx context.Context, req *pb.PlaylistRequest) (*pb.PlaylistReply, error) {
+	pl, err := s.playlist(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.PlaylistReply{Playlist: newPlaylist(pl)}, nil
+}
+
+func (s *Server) ListPlaylists(ctx context.Context, req *pb.ListPlaylistsRequest) (*pb.ListPlaylistsReply, error) {
+	limit, offset := int(req.GetLimit()), int(req.GetOffset())
+
+	if limit == 0 {
+		limit = defaultLimit
+	}
+
+	if limit < 0 {
+		return nil, ErrInvalidLimit
+	}
+
+	if offset < 0 {
+		return nil, ErrInvalidOffset
+	}
+
+	userId, restricted := ownerFilter(ctx)
+
+	found, err := s.service.ListPlaylists(service.ListOptions{
+		Name:       req.GetName(),
+		Owner:      userId,
+		Restricted: restricted,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reply := &pb.ListPlaylistsReply{Total: int32(len(found))}
+
+	for _, pl := range service.Page(found, offset, limit) {
+		reply.Playlists = append(reply.Playlists, newPlaylist(pl))
+	}
+
+	return reply, nil
+}
+
+func (s *Server) AddSong(ctx context.Context, req *pb.AddSongRequest) (*pb.PlaylistReply, error) {
+	dbsns := songsInput(req.GetSongs())
+
+	if len(dbsns) < 1 {
+		return nil, ErrNoSongsProvided
+	}
+
+	if err := service.ValidateSongs(dbsns); err != nil {
+		return nil, err
+	}
+
+	pl, err := s.playlist(ctx, req.GetPlaylistId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.service.AddSongs(ctx, pl.Id, dbsns, -1); err != nil {
+		return nil, err
+	}
+
+	return &pb.PlaylistReply{Playlist: newPlaylist(pl)}, nil
+}
+
+func (s *Server) Launch(ctx context.Context, req *pb.PlaylistRequest) (*pb.MessageReply, error) {
+	pl, err := s.playlist(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.service.LaunchPlaylist(s.ctx, pl.Id, trace.LinkFromContext(ctx)); err != nil {
+		return nil, err
+	}
+
+	return &pb.MessageReply{Id: req.GetId(), Message: "playlist is processing"}, nil
+}
+
+func (s *Server) Stop(ctx context.Context, req *pb.PlaylistRequest) (*pb.MessageReply, error) {
+	pl, err := s.playlist(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.service.StopLaunch(pl.Id); err != nil {
+		return nil, err
+	}
+
+	return &pb.MessageReply{Id: req.GetId(), Message: "playlist stopped"}, nil
+}
+
+func (s *Server) Play(ctx context.Context, req *pb.PlaylistRequest) (*pb.MessageReply, error) {
+	pl, err := s.playlist(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pl.Play(); err != nil {
+		return nil, err
+	}
+
+	return &pb.MessageReply{Id: req.GetId(), Message: "playlist is playing"}, nil
+}
+
+func (s *Server) Pause(ctx context.Context, req *pb.PlaylistRequest) (*pb.MessageReply, error) {
+	pl, err := s.playlist(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pl.Pause(); err != nil {
+		return nil, err
+	}
+
+	return &pb.MessageReply{Id: req.GetId(), Message: "playlist paused"}, nil
+}
+
+func (s *Server) Next(ctx context.Context, req *pb.PlaylistRequest) (*pb.MessageReply, error) {
+	pl, err := s.playlist(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pl.Next(); err != nil {
+		return nil, err
+	}
+
+	return &pb.MessageReply{Id: req.GetId(), Message: "playlist switched to next song"}, nil
+}
+
+func (s *Server) Prev(ctx context.Context, req *pb.PlaylistRequest) (*pb.MessageReply, error) {
+	pl, err := s.playlist(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pl.Prev(); err != nil {
+		return nil, err
+	}
+
+	return &pb.MessageReply{Id: req.GetId(), Message: "playlist switched to prev song"}, nil
+}
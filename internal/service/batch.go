@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gocloudcamp_test/internal/auth"
+	"gocloudcamp_test/internal/database"
+)
+
+type PlaylistInput struct {
+	Name   string
+	Songs  []database.Song
+	Owner  string `json:"-"`
+	Volume *uint  `json:"-"`
+}
+
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+func (s *Service) CreatePlaylists(ctx context.Context, inputs []PlaylistInput) ([]uint, error) {
+	var owner string
+
+	if claims, ok := auth.FromContext(ctx); ok {
+		owner = claims.UserId
+	}
+
+	pls := make([]database.Playlist, len(inputs))
+	sns := make([][]database.Song, len(inputs))
+	seen := make(map[string]bool, len(inputs))
+
+	for i, in := range inputs {
+		name, err := NormalizeName(in.Name)
+		if err != nil {
+			return nil, &BatchError{Index: i, Err: err}
+		}
+
+		if err := ValidateSongs(in.Songs); err != nil {
+			return nil, &BatchError{Index: i, Err: err}
+		}
+
+		if in.Volume != nil {
+			if err := ValidateVolume(int(*in.Volume)); err != nil {
+				return nil, &BatchError{Index: i, Err: err}
+			}
+		}
+
+		if s.config.UniqueNames {
+			if err := s.checkName(0, name); err != nil {
+				return nil, &BatchError{Index: i, Err: err}
+			}
+
+			if seen[strings.ToLower(name)] {
+				return nil, &BatchError{Index: i, Err: ErrDuplicateName}
+			}
+
+			seen[strings.ToLower(name)] = true
+		}
+
+		pls[i] = database.Playlist{Name: name, OwnerId: owner, Volume: in.Volume}
+
+		if in.Owner != "" {
+			pls[i].OwnerId = in.Owner
+		}
+		sns[i] = append([]database.Song(nil), in.Songs...)
+	}
+
+	if err := s.db.CreatePlaylistsWithSongs(ctx, pls, sns); err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(pls))
+
+	for i, pl := range pls {
+		if err := s.AddPlaylist(pl.Id, pl.Name, pl.OwnerId); err != nil {
+			return nil, err
+		}
+
+		if pl.Volume != nil {
+			s.setVolume(pl.Id, *pl.Volume)
+		}
+
+		for _, sn := range sns[i] {
+			if err := s.addSong(pl.Id, sn); err != nil {
+				return nil, err
+			}
+		}
+
+		ids[i] = pl.Id
+	}
+
+	return ids, nil
+}
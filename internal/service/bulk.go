@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+	"sort"
+
+	"gocloudcamp_test/internal/playlist"
+)
+
+type BulkResult struct {
+	Affected int
+	Errors   map[uint]error
+}
+
+func (s *Service) StopAll(owner string, restricted bool) BulkResult {
+	return s.forLaunched(owner, restricted, func(id uint, pl *playlist.Playlist) error {
+		return s.StopLaunch(id)
+	}, playlist.ErrAlreadyStopped)
+}
+
+func (s *Service) PauseAll(owner string, restricted bool) BulkResult {
+	return s.forLaunched(owner, restricted, func(id uint, pl *playlist.Playlist) error {
+		return pl.Pause()
+	}, playlist.ErrAlreadyPaused, playlist.ErrNotProcessed)
+}
+
+func (s *Service) forLaunched(owner string, restricted bool, fn func(uint, *playlist.Playlist) error, skip ...error) BulkResult {
+	result := BulkResult{Errors: make(map[uint]error)}
+
+	for _, id := range s.launchedIds() {
+		pl, err := s.GetPlaylist(id)
+		if err != nil {
+			continue
+		}
+
+		if restricted && pl.Owner != owner {
+			continue
+		}
+
+		if err := fn(id, pl); err != nil {
+			if errors.Is(err, ErrPlaylistNotFound) || skipped(err, skip...) {
+				continue
+			}
+
+			result.Errors[id] = err
+
+			continue
+		}
+
+		result.Affected++
+	}
+
+	return result
+}
+
+func (s *Service) launchedIds() []uint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]uint, 0, len(s.workers))
+
+	for id := range s.workers {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
+
+func skipped(err error, targets ...error) bool {
+	for _, target := range targets {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
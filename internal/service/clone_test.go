@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestClonePlaylistName(t *testing.T) {
+	tests := []struct {
+		name   string
+		unique bool
+		names  []string
+		want   []string
+		err    error
+	}{
+		{"default name", false, []string{""}, []string{"Mix (copy)"}, nil},
+		{"repeated default names", true, []string{"", "", ""}, []string{"Mix (copy)", "Mix (copy 2)", "Mix (copy 3)"}, nil},
+		{"repeated default names without unique index", false, []string{"", ""}, []string{"Mix (copy)", "Mix (copy)"}, nil},
+		{"explicit name", true, []string{"Other"}, []string{"Other"}, nil},
+		{"explicit duplicate", true, []string{"mix"}, nil, ErrDuplicateName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestService(t, Config{UniqueNames: tt.unique})
+			pl := createTestPlaylist(t, s, "Mix", 10, 20)
+
+			for i, name := range tt.names {
+				clone, err := s.ClonePlaylist(context.Background(), pl.Id, name)
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("clone %d: err %v, want %v", i, err, tt.err)
+				}
+
+				if err != nil {
+					return
+				}
+
+				if clone.Name != tt.want[i] {
+					t.Fatalf("clone %d: name %q, want %q", i, clone.Name, tt.want[i])
+				}
+
+				cloned, err := s.GetPlaylist(clone.Id)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if got := cloned.SongCount(); got != 2 {
+					t.Fatalf("clone %d: %d songs, want 2", i, got)
+				}
+			}
+		})
+	}
+}
+
+func TestClonePlaylistNameTruncated(t *testing.T) {
+	s, _ := newTestService(t, Config{UniqueNames: true})
+	pl := createTestPlaylist(t, s, strings.Repeat("ж", MaxNameLength), 10)
+
+	clone, err := s.ClonePlaylist(context.Background(), pl.Id, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len([]rune(clone.Name)); n != MaxNameLength || !strings.HasSuffix(clone.Name, " (copy)") {
+		t.Fatalf("name %q with %d runes, want %d runes ending in (copy)", clone.Name, n, MaxNameLength)
+	}
+}
+
+func TestClonePlaylistNameRace(t *testing.T) {
+	s, store := newTestService(t, Config{UniqueNames: true})
+	pl := createTestPlaylist(t, s, "Mix", 10)
+
+	conflicts := 1
+
+	store.Fail(func(query string) error {
+		if strings.HasPrefix(query, `INSERT INTO "playlists"`) && conflicts > 0 {
+			conflicts--
+
+			return &pgconn.PgError{Code: "23505", ConstraintName: "idx_playlists_name_lower"}
+		}
+
+		return nil
+	})
+
+	clone, err := s.ClonePlaylist(context.Background(), pl.Id, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if clone.Name != "Mix (copy 2)" {
+		t.Fatalf("name %q after a concurrent clone took the first copy name, want %q", clone.Name, "Mix (copy 2)")
+	}
+}
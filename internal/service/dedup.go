@@ -0,0 +1,33 @@
+package service
+
+import (
+	"gocloudcamp_test/internal/database"
+	"gocloudcamp_test/internal/playlist"
+)
+
+type songKey struct {
+	name     string
+	duration uint
+}
+
+func DedupSongs(existing []playlist.Song, dbsns []database.Song) ([]database.Song, int) {
+	seen := make(map[songKey]bool, len(existing)+len(dbsns))
+
+	for _, sn := range existing {
+		seen[songKey{sn.Name, sn.Duration}] = true
+	}
+
+	unique := make([]database.Song, 0, len(dbsns))
+
+	for _, sn := range dbsns {
+		key := songKey{sn.Name, uint(sn.Duration)}
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		unique = append(unique, sn)
+	}
+
+	return unique, len(dbsns) - len(unique)
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"gocloudcamp_test/internal/database"
+	"gocloudcamp_test/internal/export"
+)
+
+func (s *Service) ExportPlaylist(id uint) (database.Playlist, []database.Song, error) {
+	pl, err := s.GetPlaylist(id)
+	if err != nil {
+		return database.Playlist{}, nil, err
+	}
+
+	status := pl.Status()
+	volume := pl.Volume()
+
+	dbpl := database.Playlist{
+		Id:        status.Id,
+		Name:      status.Name,
+		OwnerId:   pl.Owner,
+		Volume:    &volume,
+		CreatedAt: status.CreatedAt,
+		UpdatedAt: status.UpdatedAt,
+	}
+
+	var dbsns []database.Song
+
+	for i, sn := range pl.GetSongsList() {
+		dbsns = append(dbsns, database.Song{
+			SongId:     sn.Id,
+			PlaylistId: id,
+			Name:       sn.Name,
+			Duration:   database.Duration(sn.Duration),
+			Tags:       sn.Tags,
+			Favorite:   sn.Favorite,
+			Position:   i,
+			CreatedAt:  sn.CreatedAt,
+			UpdatedAt:  sn.UpdatedAt,
+		})
+	}
+
+	return dbpl, dbsns, nil
+}
+
+func (s *Service) ImportPlaylist(ctx context.Context, name string, r io.Reader) (uint, error) {
+	dbsns, err := export.ParseM3U(r, s.config.ImportDuration)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(dbsns) == 0 {
+		return 0, ErrNoSongs
+	}
+
+	ids, err := s.CreatePlaylists(ctx, []PlaylistInput{{Name: name, Songs: dbsns}})
+	if err != nil {
+		var batchErr *BatchError
+
+		if errors.As(err, &batchErr) {
+			return 0, batchErr.Err
+		}
+
+		return 0, err
+	}
+
+	return ids[0], nil
+}
+
+func (s *Service) ExportAll(fn func(database.Playlist, []database.Song) error) error {
+	for _, pl := range s.sortedPlaylists() {
+		dbpl, dbsns, err := s.ExportPlaylist(pl.Id)
+		if errors.Is(err, ErrPlaylistNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := fn(dbpl, dbsns); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) RestoreBackup(ctx context.Context, backup export.Backup) ([]uint, error) {
+	if backup.Version < 1 || backup.Version > export.BackupVersion {
+		return nil, export.ErrBackupVersion
+	}
+
+	inputs := make([]PlaylistInput, 0, len(backup.Playlists))
+
+	for _, bpl := range backup.Playlists {
+		in := PlaylistInput{Name: bpl.Name, Owner: bpl.Owner, Volume: bpl.Volume}
+
+		for _, bsn := range bpl.Songs {
+			in.Songs = append(in.Songs, database.Song{Name: bsn.Name, Duration: bsn.Duration, Tags: bsn.Tags, Favorite: bsn.Favorite})
+		}
+
+		inputs = append(inputs, in)
+	}
+
+	if len(inputs) == 0 {
+		return []uint{}, nil
+	}
+
+	return s.CreatePlaylists(ctx, inputs)
+}
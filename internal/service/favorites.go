@@ -0,0 +1,46 @@
+package service
+
+import "context"
+
+func (s *Service) SetFavorite(ctx context.Context, id uint, sid uint, favorite bool) error {
+	pl, err := s.GetPlaylist(id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := pl.GetSong(sid); err != nil {
+		return err
+	}
+
+	if err := s.db.SetFavorite(ctx, id, sid, favorite); err != nil {
+		return err
+	}
+
+	if err := pl.SetFavorite(sid, favorite); err != nil {
+		return err
+	}
+
+	pl.BumpVersion()
+
+	return nil
+}
+
+func (s *Service) Favorites(owner string, restricted bool) []SongMatch {
+	pls := s.sortedPlaylists()
+
+	if restricted {
+		pls = OwnedBy(pls, owner)
+	}
+
+	matches := []SongMatch{}
+
+	for _, pl := range pls {
+		for _, sn := range pl.GetSongsList() {
+			if sn.Favorite {
+				matches = append(matches, SongMatch{PlaylistId: pl.Id, Song: sn})
+			}
+		}
+	}
+
+	return matches
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"crypto/sha256"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrIdempotencyMismatch = errors.New("idempotency key was used with a different request body")
+
+type idempotencyEntry struct {
+	sync.Mutex
+	id      uint
+	hash    [sha256.Size]byte
+	expires time.Time
+}
+
+func (s *Service) idempotencyFor(key string) *idempotencyEntry {
+	s.idemMu.Lock()
+	defer s.idemMu.Unlock()
+
+	now := time.Now()
+
+	for k, e := range s.idempotency {
+		if now.After(e.expires) {
+			delete(s.idempotency, k)
+		}
+	}
+
+	e, ok := s.idempotency[key]
+	if !ok {
+		e = &idempotencyEntry{expires: now.Add(s.config.IdempotencyTTL)}
+		s.idempotency[key] = e
+	}
+
+	return e
+}
+
+func (s *Service) Idempotent(userId string, key string, body []byte, create func() (uint, error)) (uint, bool, error) {
+	if key == "" {
+		id, err := create()
+
+		return id, false, err
+	}
+
+	hash := sha256.Sum256(body)
+
+	e := s.idempotencyFor(userId + ":" + key)
+
+	e.Lock()
+	defer e.Unlock()
+
+	if e.id != 0 {
+		if _, err := s.GetPlaylist(e.id); err == nil {
+			if e.hash != hash {
+				return 0, false, ErrIdempotencyMismatch
+			}
+
+			return e.id, true, nil
+		}
+	}
+
+	id, err := create()
+	if err != nil {
+		return 0, false, err
+	}
+
+	s.idemMu.Lock()
+	e.id = id
+	e.hash = hash
+	e.expires = time.Now().Add(s.config.IdempotencyTTL)
+	s.idemMu.Unlock()
+
+	return id, false, nil
+}
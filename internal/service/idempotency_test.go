@@ -0,0 +1,126 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func countingCreate(s *Service, created *atomic.Uint32) func() (uint, error) {
+	return func() (uint, error) {
+		id := uint(created.Add(1))
+
+		time.Sleep(10 * time.Millisecond)
+
+		return id, s.AddPlaylist(id, "playlist", "")
+	}
+}
+
+func TestIdempotentRetry(t *testing.T) {
+	s, _ := newTestService(t, Config{})
+
+	var created atomic.Uint32
+
+	body := []byte(`{"Name":"playlist"}`)
+
+	first, replayed, err := s.Idempotent("alice", "key", body, countingCreate(s, &created))
+	if err != nil || replayed {
+		t.Fatalf("first request: id %d, replayed %t, err %v", first, replayed, err)
+	}
+
+	second, replayed, err := s.Idempotent("alice", "key", body, countingCreate(s, &created))
+	if err != nil || !replayed {
+		t.Fatalf("retry: id %d, replayed %t, err %v", second, replayed, err)
+	}
+
+	if first != second {
+		t.Fatalf("retry returned playlist %d, want %d", second, first)
+	}
+
+	if n := created.Load(); n != 1 {
+		t.Fatalf("created %d playlists, want 1", n)
+	}
+}
+
+func TestIdempotentScope(t *testing.T) {
+	body := []byte(`{"Name":"playlist"}`)
+
+	tests := []struct {
+		name     string
+		userId   string
+		key      string
+		body     []byte
+		replayed bool
+		err      error
+	}{
+		{"same user and body", "alice", "key", body, true, nil},
+		{"another user", "bob", "key", body, false, nil},
+		{"another key", "alice", "other", body, false, nil},
+		{"another body", "alice", "key", []byte(`{"Name":"other"}`), false, ErrIdempotencyMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestService(t, Config{})
+
+			var created atomic.Uint32
+
+			original, _, err := s.Idempotent("alice", "key", body, countingCreate(s, &created))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			id, replayed, err := s.Idempotent(tt.userId, tt.key, tt.body, countingCreate(s, &created))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err %v, want %v", err, tt.err)
+			}
+
+			if replayed != tt.replayed {
+				t.Fatalf("replayed %t, want %t", replayed, tt.replayed)
+			}
+
+			if err == nil && replayed != (id == original) {
+				t.Fatalf("got playlist %d, original %d, replayed %t", id, original, replayed)
+			}
+		})
+	}
+}
+
+func TestIdempotentConcurrent(t *testing.T) {
+	s, _ := newTestService(t, Config{})
+
+	var created atomic.Uint32
+	var wg sync.WaitGroup
+
+	body := []byte(`{"Name":"playlist"}`)
+	ids := make([]uint, 16)
+
+	for i := range ids {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			id, _, err := s.Idempotent("alice", "key", body, countingCreate(s, &created))
+			if err != nil {
+				t.Error(err)
+			}
+
+			ids[i] = id
+		}(i)
+	}
+
+	wg.Wait()
+
+	if n := created.Load(); n != 1 {
+		t.Fatalf("created %d playlists, want 1", n)
+	}
+
+	for i, id := range ids {
+		if id != ids[0] {
+			t.Fatalf("request %d got playlist %d, want %d", i, id, ids[0])
+		}
+	}
+}
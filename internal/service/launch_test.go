@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"gocloudcamp_test/internal/playlist"
+)
+
+func TestLaunchEmptyPlaylist(t *testing.T) {
+	s, _ := newTestService(t, Config{})
+	pl := createTestPlaylist(t, s, "empty")
+
+	if err := s.LaunchPlaylist(context.Background(), pl.Id); !errors.Is(err, ErrNoSongs) {
+		t.Fatalf("err %v, want %v", err, ErrNoSongs)
+	}
+
+	if n := s.Workers().Launched; n != 0 {
+		t.Fatalf("%d active workers, want 0", n)
+	}
+}
+
+func serviceGoroutines() int {
+	buf := make([]byte, 1<<20)
+	buf = buf[:runtime.Stack(buf, true)]
+
+	n := 0
+
+	for _, stack := range strings.Split(string(buf), "\n\n") {
+		if strings.Contains(stack, "gocloudcamp_test/internal/") && !strings.Contains(stack, "testing.tRunner") {
+			n++
+		}
+	}
+
+	return n
+}
+
+func TestStopLaunchReleasesGoroutines(t *testing.T) {
+	s, _ := newTestService(t, Config{})
+	pl := createTestPlaylist(t, s, "stop", 60)
+
+	baseline := serviceGoroutines()
+
+	for i := 0; i < 3; i++ {
+		launchTestPlaylist(t, s, pl)
+
+		if err := s.StopLaunch(pl.Id); err != nil {
+			t.Fatalf("stop %d: %v", i, err)
+		}
+
+		if pl.IsProcessing() {
+			t.Fatalf("playlist still processing after stop %d", i)
+		}
+
+		if n := s.Workers().Launched; n != 0 {
+			t.Fatalf("%d active workers after stop %d, want 0", n, i)
+		}
+	}
+
+	deadline := time.Now().Add(time.Second)
+
+	for serviceGoroutines() > baseline {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d service goroutines after stop, want %d", serviceGoroutines(), baseline)
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestStopLaunchNotLaunched(t *testing.T) {
+	s, _ := newTestService(t, Config{})
+	pl := createTestPlaylist(t, s, "stop", 60)
+
+	if err := s.StopLaunch(pl.Id); !errors.Is(err, playlist.ErrAlreadyStopped) {
+		t.Fatalf("err %v, want %v", err, playlist.ErrAlreadyStopped)
+	}
+}
+
+func TestShutdownWaitsForWorkers(t *testing.T) {
+	s, _ := newTestService(t, Config{})
+	s.Start()
+
+	var pls []*playlist.Playlist
+
+	for i := 0; i < 3; i++ {
+		pl := createTestPlaylist(t, s, fmt.Sprintf("shutdown %d", i), 60)
+
+		launchTestPlaylist(t, s, pl)
+
+		if err := pl.Play(); err != nil {
+			t.Fatal(err)
+		}
+
+		pls = append(pls, pl)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	if n := s.Workers().Launched; n != 0 {
+		t.Fatalf("%d active workers after shutdown, want 0", n)
+	}
+
+	for _, pl := range pls {
+		if pl.IsProcessing() {
+			t.Fatalf("playlist %d still processing after shutdown", pl.Id)
+		}
+	}
+
+	if _, ok := <-s.ChanErrorLog; ok {
+		t.Fatal("error log channel still open after shutdown")
+	}
+}
+
+func TestLaunchDuringShutdown(t *testing.T) {
+	s, _ := newTestService(t, Config{})
+	s.Start()
+
+	pl := createTestPlaylist(t, s, "shutdown", 60)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	if err := s.LaunchPlaylist(context.Background(), pl.Id); !errors.Is(err, ErrShuttingDown) {
+		t.Fatalf("launch err %v, want %v", err, ErrShuttingDown)
+	}
+
+	if err := s.SchedulePlaylist(context.Background(), pl.Id, time.Now().Add(time.Hour)); !errors.Is(err, ErrShuttingDown) {
+		t.Fatalf("schedule err %v, want %v", err, ErrShuttingDown)
+	}
+
+	if n := s.Workers().Launched; n != 0 {
+		t.Fatalf("%d active workers after shutdown, want 0", n)
+	}
+
+	if pl.IsProcessing() {
+		t.Fatal("playlist processing after shutdown")
+	}
+}
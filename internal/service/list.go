@@ -0,0 +1,180 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gocloudcamp_test/internal/database"
+	"gocloudcamp_test/internal/playlist"
+)
+
+var (
+	ErrInvalidStatus = errors.New("status must be one of playing, paused, stopped")
+	ErrInvalidSort   = errors.New("sort must be one of name, -name, duration, -duration")
+	ErrInvalidCursor = errors.New("cursor is invalid or points to a removed song")
+)
+
+type SongsPage struct {
+	Songs      []playlist.Song
+	NextCursor string
+}
+
+type ListOptions struct {
+	Name       string
+	Owner      string
+	Restricted bool
+	Status     playlist.State
+	Sort       string
+}
+
+func ParseState(value string) (playlist.State, error) {
+	switch st := playlist.State(value); st {
+	case playlist.StatePlaying, playlist.StatePaused, playlist.StateStopped:
+		return st, nil
+	}
+
+	return "", ErrInvalidStatus
+}
+
+func ParseSort(value string) (string, error) {
+	switch value {
+	case "name", "-name", "duration", "-duration":
+		return value, nil
+	}
+
+	return "", ErrInvalidSort
+}
+
+func SortPlaylists(pls []*playlist.Playlist, key string) {
+	if key == "" {
+		return
+	}
+
+	desc := strings.HasPrefix(key, "-")
+	key = strings.TrimPrefix(key, "-")
+
+	names := make(map[*playlist.Playlist]string, len(pls))
+	durations := make(map[*playlist.Playlist]uint64, len(pls))
+
+	for _, pl := range pls {
+		names[pl] = strings.ToLower(pl.Status().Name)
+		durations[pl] = pl.TotalDuration()
+	}
+
+	sort.SliceStable(pls, func(i, j int) bool {
+		a, b := pls[i], pls[j]
+
+		if desc {
+			a, b = b, a
+		}
+
+		if key == "duration" {
+			return durations[a] < durations[b]
+		}
+
+		return names[a] < names[b]
+	})
+}
+
+func FilterState(pls []*playlist.Playlist, state playlist.State) []*playlist.Playlist {
+	filtered := make([]*playlist.Playlist, 0, len(pls))
+
+	for _, pl := range pls {
+		if pl.Status().State() == state {
+			filtered = append(filtered, pl)
+		}
+	}
+
+	return filtered
+}
+
+func (s *Service) ListPlaylists(opts ListOptions) ([]*playlist.Playlist, error) {
+	var pls []*playlist.Playlist
+
+	if opts.Name != "" {
+		found, err := s.SearchPlaylists(opts.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		pls = found
+	} else {
+		pls = s.sortedPlaylists()
+	}
+
+	if opts.Restricted {
+		pls = OwnedBy(pls, opts.Owner)
+	}
+
+	if opts.Status != "" {
+		pls = FilterState(pls, opts.Status)
+	}
+
+	SortPlaylists(pls, opts.Sort)
+
+	return pls, nil
+}
+
+func encodeCursor(sid uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(uint64(sid), 10)))
+}
+
+func decodeCursor(cursor string) (uint, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+
+	sid, err := strconv.ParseUint(string(raw), 10, 0)
+	if err != nil || sid == 0 {
+		return 0, ErrInvalidCursor
+	}
+
+	return uint(sid), nil
+}
+
+func (s *Service) GetSongsPage(plId uint, cursor string, limit int, tag string) (SongsPage, error) {
+	sid, err := decodeCursor(cursor)
+	if err != nil {
+		return SongsPage{}, err
+	}
+
+	pl, err := s.GetPlaylist(plId)
+	if err != nil {
+		return SongsPage{}, err
+	}
+
+	var match func(playlist.Song) bool
+
+	if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+		match = func(sn playlist.Song) bool { return database.Tags(sn.Tags).Has(tag) }
+	}
+
+	sns, more, err := pl.SongsAfter(sid, limit, match)
+	if errors.Is(err, playlist.ErrSongNotIn) {
+		return SongsPage{}, ErrInvalidCursor
+	}
+
+	if err != nil {
+		return SongsPage{}, err
+	}
+
+	if sns == nil {
+		sns = []playlist.Song{}
+	}
+
+	page := SongsPage{Songs: sns}
+
+	if more && len(sns) > 0 {
+		page.NextCursor = encodeCursor(sns[len(sns)-1].Id)
+	}
+
+	return page, nil
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"gocloudcamp_test/internal/database"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+		err  error
+	}{
+		{"empty", "", "", ErrInvalidName},
+		{"spaces", "   ", "", ErrInvalidName},
+		{"whitespace", "\t\n  ", "", ErrInvalidName},
+		{"control characters", "\x00\x07\x1b", "", ErrInvalidName},
+		{"trimmed", "  road trip  ", "road trip", nil},
+		{"inner control stripped", "road\x00 trip", "road trip", nil},
+		{"ascii at limit", strings.Repeat("a", MaxNameLength), strings.Repeat("a", MaxNameLength), nil},
+		{"ascii over limit", strings.Repeat("a", MaxNameLength+1), "", ErrInvalidName},
+		{"two byte runes at limit", strings.Repeat("é", MaxNameLength), strings.Repeat("é", MaxNameLength), nil},
+		{"two byte runes over limit", strings.Repeat("é", MaxNameLength+1), "", ErrInvalidName},
+		{"four byte runes at limit", strings.Repeat("🎵", MaxNameLength), strings.Repeat("🎵", MaxNameLength), nil},
+		{"four byte runes over limit", strings.Repeat("🎵", MaxNameLength+1), "", ErrInvalidName},
+		{"cyrillic", "  Плейлист  ", "Плейлист", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeName(tt.in)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err %v, want %v", err, tt.err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("name %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeNameNFC(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"precomposed", "Café", "Café"},
+		{"combining acute", "Café", "Café"},
+		{"combining with control", "Café\u0000", "Café"},
+		{"hangul jamo", "가", "가"},
+		{"combining counted once", strings.Repeat("é", MaxNameLength), strings.Repeat("é", MaxNameLength)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeName(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("name %+q, want %+q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUniqueNameNFC(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		in       string
+	}{
+		{"combining after precomposed", "Café", "Café"},
+		{"precomposed after combining", "Café", "Café"},
+		{"different case", "Café", "CAFÉ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestService(t, Config{UniqueNames: true})
+			createTestPlaylist(t, s, tt.existing)
+
+			err := s.CreatePlaylist(context.Background(), &database.Playlist{Name: tt.in})
+			if !errors.Is(err, ErrDuplicateName) {
+				t.Fatalf("create err %v, want %v", err, ErrDuplicateName)
+			}
+
+			other := createTestPlaylist(t, s, "other")
+
+			if err := s.EditPlaylist(context.Background(), other.Id, tt.in); !errors.Is(err, ErrDuplicateName) {
+				t.Fatalf("rename err %v, want %v", err, ErrDuplicateName)
+			}
+		})
+	}
+}
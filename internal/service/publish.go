@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"gocloudcamp_test/internal/playlist"
+)
+
+type PlaybackEvent struct {
+	PlaylistId uint           `json:"playlist_id"`
+	Event      playlist.Event `json:"event"`
+	SongId     uint           `json:"song_id,omitempty"`
+	Timestamp  time.Time      `json:"timestamp"`
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, ev PlaybackEvent) error
+}
+
+func (s *Service) watchEvents(ctx context.Context, pl *playlist.Playlist, events <-chan playlist.Event) {
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+
+			s.handleEvent(pl, ev)
+		case <-ctx.Done():
+			for {
+				select {
+				case ev, ok := <-events:
+					if !ok {
+						return
+					}
+
+					s.handleEvent(pl, ev)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *Service) handleEvent(pl *playlist.Playlist, ev playlist.Event) {
+	s.notifyWebhook(pl, ev)
+	s.publishEvent(context.Background(), pl, ev)
+	s.syncEvent(pl, ev)
+}
+
+func (s *Service) publishEvent(ctx context.Context, pl *playlist.Playlist, ev playlist.Event) {
+	if s.config.Publisher == nil {
+		return
+	}
+
+	st := pl.Status()
+
+	err := s.config.Publisher.Publish(ctx, PlaybackEvent{
+		PlaylistId: st.Id,
+		Event:      ev,
+		SongId:     st.CurrentId,
+		Timestamp:  time.Now().UTC(),
+	})
+	if err != nil {
+		s.LogError("publish event", err, slog.Uint64("playlist_id", uint64(st.Id)), slog.String("event", string(ev)))
+	}
+}
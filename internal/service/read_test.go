@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gocloudcamp_test/internal/database"
+	"gocloudcamp_test/internal/playlist"
+)
+
+func TestGetPlaylistAfterMutation(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(s *Service, pl *playlist.Playlist, sid uint) error
+		check  func(t *testing.T, s *Service, id uint, sid uint)
+	}{
+		{
+			"edit playlist",
+			func(s *Service, pl *playlist.Playlist, _ uint) error { return s.EditPlaylist(context.Background(), pl.Id, "renamed") },
+			func(t *testing.T, s *Service, id uint, _ uint) {
+				if name := getTestPlaylist(t, s, id).Status().Name; name != "renamed" {
+					t.Fatalf("name %q, want %q", name, "renamed")
+				}
+			},
+		},
+		{
+			"add song",
+			func(s *Service, pl *playlist.Playlist, _ uint) error {
+				return s.CreateSong(context.Background(), &database.Song{PlaylistId: pl.Id, Name: "added", Duration: 30})
+			},
+			func(t *testing.T, s *Service, id uint, _ uint) {
+				if n := len(getTestPlaylist(t, s, id).GetSongsList()); n != 3 {
+					t.Fatalf("%d songs, want 3", n)
+				}
+			},
+		},
+		{
+			"edit song",
+			func(s *Service, pl *playlist.Playlist, sid uint) error {
+				return s.EditSong(context.Background(), pl.Id, sid, "edited", 90, nil)
+			},
+			func(t *testing.T, s *Service, id uint, sid uint) {
+				sn, err := getTestPlaylist(t, s, id).GetSong(sid)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if sn.Name != "edited" || sn.Duration != 90 {
+					t.Fatalf("song %q %d, want %q %d", sn.Name, sn.Duration, "edited", 90)
+				}
+			},
+		},
+		{
+			"delete song",
+			func(s *Service, pl *playlist.Playlist, sid uint) error { return s.DeleteSong(context.Background(), pl.Id, sid) },
+			func(t *testing.T, s *Service, id uint, sid uint) {
+				if _, err := getTestPlaylist(t, s, id).GetSong(sid); !errors.Is(err, playlist.ErrSongNotIn) {
+					t.Fatalf("err %v, want %v", err, playlist.ErrSongNotIn)
+				}
+			},
+		},
+		{
+			"delete playlist",
+			func(s *Service, pl *playlist.Playlist, _ uint) error { return s.DeletePlaylist(context.Background(), pl.Id) },
+			func(t *testing.T, s *Service, id uint, _ uint) {
+				if _, err := s.GetPlaylist(id); !errors.Is(err, ErrPlaylistNotFound) {
+					t.Fatalf("err %v, want %v", err, ErrPlaylistNotFound)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, store := newTestService(t, Config{})
+			pl := createTestPlaylist(t, s, "read", 60, 60)
+			sid := songIds(pl)[0]
+
+			getTestPlaylist(t, s, pl.Id)
+
+			if err := tt.mutate(s, pl, sid); err != nil {
+				t.Fatalf("mutate: %v", err)
+			}
+
+			store.Reset()
+
+			tt.check(t, s, pl.Id, sid)
+
+			if queries := store.Queries(); len(queries) != 0 {
+				t.Fatalf("read after mutation queried the database: %q", queries)
+			}
+		})
+	}
+}
+
+func getTestPlaylist(t *testing.T, s *Service, id uint) *playlist.Playlist {
+	t.Helper()
+
+	pl, err := s.GetPlaylist(id)
+	if err != nil {
+		t.Fatalf("get playlist %d: %v", id, err)
+	}
+
+	return pl
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"log/slog"
+	"time"
+)
+
+var (
+	ErrScheduleInPast   = errors.New("schedule time must be in the future")
+	ErrScheduleNotFound = errors.New("playlist has no scheduled launch")
+)
+
+type schedule struct {
+	at     time.Time
+	cancel context.CancelFunc
+}
+
+func (s *Service) SchedulePlaylist(ctx context.Context, id uint, at time.Time) error {
+	if !at.After(time.Now()) {
+		return ErrScheduleInPast
+	}
+
+	if _, err := s.GetPlaylist(id); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.shuttingDown.Load() {
+		return ErrShuttingDown
+	}
+
+	if sc, ok := s.schedules[id]; ok {
+		sc.cancel()
+	}
+
+	scheduleCtx, cancel := context.WithCancel(ctx)
+
+	sc := &schedule{at: at.UTC(), cancel: cancel}
+
+	s.schedules[id] = sc
+
+	s.activeWg.Add(1)
+	go func() {
+		defer s.activeWg.Done()
+
+		s.scheduledLaunch(ctx, scheduleCtx, id, sc)
+	}()
+
+	log.Printf("service | schedule | id %d | at %s", id, sc.at.Format(time.RFC3339))
+
+	return nil
+}
+
+func (s *Service) UnschedulePlaylist(id uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sc, ok := s.schedules[id]
+	if !ok {
+		return ErrScheduleNotFound
+	}
+
+	sc.cancel()
+
+	delete(s.schedules, id)
+
+	log.Printf("service | unschedule | id %d", id)
+
+	return nil
+}
+
+func (s *Service) scheduledLaunch(ctx context.Context, scheduleCtx context.Context, id uint, sc *schedule) {
+	timer := time.NewTimer(time.Until(sc.at))
+	defer timer.Stop()
+
+	select {
+	case <-scheduleCtx.Done():
+		return
+	case <-timer.C:
+	}
+
+	s.mu.Lock()
+	current := s.schedules[id] == sc
+	if current {
+		delete(s.schedules, id)
+	}
+	s.mu.Unlock()
+
+	if !current {
+		return
+	}
+
+	sc.cancel()
+
+	log.Printf("service | scheduled launch | id %d", id)
+
+	if err := s.LaunchPlaylist(ctx, id); err != nil {
+		s.LogError("scheduled launch", err, slog.Uint64("playlist_id", uint64(id)))
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"gocloudcamp_test/internal/playlist"
+)
+
+var ErrEmptyQuery = errors.New("search query must not be empty")
+
+type PlaylistRef struct {
+	Id   uint   `json:"id" xml:"id"`
+	Name string `json:"name" xml:"name"`
+}
+
+type SongMatch struct {
+	PlaylistId uint          `json:"playlist_id" xml:"playlist_id"`
+	Song       playlist.Song `json:"song" xml:"song"`
+}
+
+func (s *Service) SearchSongs(ctx context.Context, query string, limit int, owner string, restricted bool) ([]SongMatch, error) {
+	if query = strings.TrimSpace(query); query == "" {
+		return nil, ErrEmptyQuery
+	}
+
+	dbsns, err := s.db.SearchSongs(ctx, query, limit, owner, restricted)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]SongMatch, 0, len(dbsns))
+
+	for _, dbsn := range dbsns {
+		pl, err := s.GetPlaylist(dbsn.PlaylistId)
+		if err != nil {
+			continue
+		}
+
+		sn, err := pl.GetSong(dbsn.SongId)
+		if err != nil {
+			continue
+		}
+
+		matches = append(matches, SongMatch{PlaylistId: dbsn.PlaylistId, Song: sn})
+	}
+
+	return matches, nil
+}
+
+func (s *Service) PlaylistsContainingSong(name string) []*playlist.Playlist {
+	var found []*playlist.Playlist
+
+	for _, pl := range s.sortedPlaylists() {
+		for _, sn := range pl.GetSongsList() {
+			if sn.Name == name {
+				found = append(found, pl)
+
+				break
+			}
+		}
+	}
+
+	return found
+}
+
+func Refs(pls []*playlist.Playlist) []PlaylistRef {
+	refs := make([]PlaylistRef, 0, len(pls))
+
+	for _, pl := range pls {
+		refs = append(refs, PlaylistRef{Id: pl.Id, Name: pl.Status().Name})
+	}
+
+	return refs
+}
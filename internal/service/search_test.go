@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"gocloudcamp_test/internal/database"
+)
+
+func createNamedSongsPlaylist(t *testing.T, s *Service, name string, songs ...string) uint {
+	t.Helper()
+
+	dbsns := make([]database.Song, len(songs))
+
+	for i, sn := range songs {
+		dbsns[i] = database.Song{Name: sn, Duration: 60}
+	}
+
+	dbpl := &database.Playlist{Name: name}
+
+	if err := s.CreatePlaylistWithSongs(context.Background(), dbpl, dbsns); err != nil {
+		t.Fatalf("create playlist: %v", err)
+	}
+
+	return dbpl.Id
+}
+
+func TestPlaylistsContainingSong(t *testing.T) {
+	s, _ := newTestService(t, Config{})
+
+	first := createNamedSongsPlaylist(t, s, "first", "intro", "intro", "outro")
+	second := createNamedSongsPlaylist(t, s, "second", "outro")
+	third := createNamedSongsPlaylist(t, s, "third", "intro")
+	createNamedSongsPlaylist(t, s, "empty")
+
+	tests := []struct {
+		song string
+		want []PlaylistRef
+	}{
+		{"intro", []PlaylistRef{{first, "first"}, {third, "third"}}},
+		{"outro", []PlaylistRef{{first, "first"}, {second, "second"}}},
+		{"Intro", []PlaylistRef{}},
+		{"intr", []PlaylistRef{}},
+		{"missing", []PlaylistRef{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.song, func(t *testing.T) {
+			if got := Refs(s.PlaylistsContainingSong(tt.song)); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("playlists %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,36 +3,190 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"gocloudcamp_test/internal/auth"
 	"gocloudcamp_test/internal/database"
+	"gocloudcamp_test/internal/metrics"
 	"gocloudcamp_test/internal/playlist"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/text/unicode/norm"
 )
 
 var (
-	ErrNoPlaylistWithId = errors.New("there is no playlist with such id")
+	ErrPlaylistNotFound = errors.New("there is no playlist with such id")
 	ErrAlreadyExists    = errors.New("playlist with this id already exists")
+	ErrPlaylistLaunched = errors.New("playlist is launched, stop it first")
+	ErrSameTarget       = errors.New("target playlist is the same as source")
+	ErrAlreadyLaunched  = errors.New("playlist is already launched")
+	ErrLaunchLimit      = errors.New("too many launched playlists")
+	ErrShuttingDown     = errors.New("service is shutting down")
+	ErrInvalidDuration  = errors.New("song duration must be between 1 and 86400 seconds")
+	ErrInvalidName      = errors.New("playlist name must be between 1 and 200 characters")
+	ErrDuplicateName    = database.ErrDuplicateName
+	ErrUnavailable      = database.ErrUnavailable
+	ErrNoSongs          = playlist.ErrNoSongs
+	ErrEndOfPlaylist    = playlist.ErrEndOfPlaylist
+	ErrStartOfPlaylist  = playlist.ErrStartOfPlaylist
+)
+
+const (
+	MaxSongDuration = 86400
+	MaxNameLength   = 200
+	maxCopyNames    = 100
 )
 
 type Playlists = map[uint]*playlist.Playlist
 
+type Config struct {
+	ProgressInterval time.Duration
+	TickInterval     time.Duration
+	PersistInterval  time.Duration
+	ResumeOnStart    bool
+	ErrorLogBuffer   int
+	MaxBodySize      int64
+	UniqueNames      bool
+	IdempotencyTTL   time.Duration
+	AuthEnabled      bool
+	AuthSecret       string
+	CorsOrigins      []string
+	RequestTimeout   time.Duration
+	MaxLaunches      int
+	ImportDuration   uint
+	HistorySize      int
+	SoftDelete       bool
+	DeleteRetention  time.Duration
+	RequireIfMatch   bool
+	RateLimit        float64
+	RateBurst        int
+	TrustedProxies   []string
+	WriteConcurrency int
+	WriteQueueWait   time.Duration
+	PprofEnabled     bool
+	Publisher        Publisher
+	Syncer           Syncer
+	NodeId           string
+	TracerProvider   trace.TracerProvider
+	Logger           *slog.Logger
+}
+
+type OpError struct {
+	Op  string
+	Err error
+}
+
+func (e *OpError) Error() string {
+	return e.Op + ": " + e.Err.Error()
+}
+
+func (e *OpError) Unwrap() error {
+	return e.Err
+}
+
+type worker struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+	sleep   context.CancelFunc
+	started time.Time
+}
+
 type Service struct {
 	db            *database.Database
+	config        Config
+	tracer        trace.Tracer
+	logger        *slog.Logger
 	activeWg      sync.WaitGroup
+	mu            sync.RWMutex
 	playlists     Playlists
+	workers       map[uint]*worker
+	shares        map[string]uint
+	schedules     map[uint]*schedule
+	webhooks      map[uint]string
+	webhookClient *http.Client
+	syncMu        sync.Mutex
+	suppressed    map[suppressKey]int
+	shuttingDown  atomic.Bool
 	ChanForceStop chan struct{}
 	ChanErrorLog  chan error
+	chanLogDone   chan struct{}
+	logMu         sync.RWMutex
+	logClosed     bool
+	idemMu        sync.Mutex
+	idempotency   map[string]*idempotencyEntry
+	editMu        sync.Mutex
+	editLocks     map[uint]*sync.Mutex
 }
 
-func New(db *database.Database) *Service {
+func New(db *database.Database, config Config) *Service {
 	service := &Service{}
 
+	if config.TracerProvider == nil {
+		config.TracerProvider = trace.NewNoopTracerProvider()
+	}
+
+	if config.IdempotencyTTL <= 0 {
+		config.IdempotencyTTL = time.Hour * 24
+	}
+
+	if config.ImportDuration == 0 {
+		config.ImportDuration = 180
+	}
+
+	if config.HistorySize <= 0 {
+		config.HistorySize = 100
+	}
+
+	if config.ProgressInterval <= 0 {
+		config.ProgressInterval = time.Second
+	}
+
+	if config.TickInterval <= 0 {
+		config.TickInterval = time.Second
+	}
+
+	if config.PersistInterval <= 0 {
+		config.PersistInterval = time.Second * 5
+	}
+
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+
+	if config.NodeId == "" {
+		config.NodeId = newNodeId()
+	}
+
 	service.db = db
+	service.config = config
+	service.tracer = config.TracerProvider.Tracer("gocloudcamp_test/internal/service")
+	service.logger = config.Logger
 	service.playlists = make(Playlists)
+	service.workers = make(map[uint]*worker)
+	service.shares = make(map[string]uint)
+	service.schedules = make(map[uint]*schedule)
+	service.webhooks = make(map[uint]string)
+	service.webhookClient = &http.Client{Timeout: webhookTimeout}
+	service.suppressed = make(map[suppressKey]int)
+	service.idempotency = make(map[string]*idempotencyEntry)
+	service.editLocks = make(map[uint]*sync.Mutex)
 
 	service.ChanForceStop = make(chan struct{}, 1)
-	service.ChanErrorLog = make(chan error)
+	service.ChanErrorLog = make(chan error, config.ErrorLogBuffer)
+	service.chanLogDone = make(chan struct{})
 
 	return service
 }
@@ -41,38 +195,138 @@ func (s *Service) Start() {
 	log.Print("service | start")
 
 	go func() {
+		defer close(s.chanLogDone)
+
 		for err := range s.ChanErrorLog {
-			if err != nil {
-				log.Printf("service | error | %v", err)
+			var opErr *OpError
+
+			if err != nil && !errors.As(err, &opErr) {
+				s.logger.Error("operation failed", slog.String("op", "unknown"), slog.Any("error", err))
 			}
 		}
 	}()
 
+	if err := s.db.SetUniqueNames(s.config.UniqueNames); err != nil {
+		s.LogError("unique names", err)
+	}
+
 	pls, err := s.db.LoadPlaylists()
 	if err != nil {
-		s.ChanErrorLog <- err
+		s.LogError("load playlists", err)
 	}
 
 	for _, pl := range pls {
-		if err := s.AddPlaylist(pl.Id, pl.Name); err != nil {
-			s.ChanErrorLog <- err
-
-			continue
+		if err := s.loadPlaylist(pl); err != nil {
+			s.LogError("add playlist", err, slog.Uint64("playlist_id", uint64(pl.Id)))
 		}
 	}
 
 	sns, err := s.db.LoadSongs()
 	if err != nil {
-		s.ChanErrorLog <- err
+		s.LogError("load songs", err)
 	}
 
 	for _, sn := range sns {
-		if err := s.AddSong(sn.PlaylistId, sn.SongId, sn.Name, sn.Duration); err != nil {
-			s.ChanErrorLog <- err
+		if err := s.loadSong(sn); err != nil {
+			s.LogError("add song", err, slog.Uint64("playlist_id", uint64(sn.PlaylistId)), slog.Uint64("song_id", uint64(sn.SongId)))
+		}
+	}
+
+	for _, dbpl := range pls {
+		if pl, err := s.GetPlaylist(dbpl.Id); err == nil {
+			pl.SetTimes(dbpl.CreatedAt, dbpl.UpdatedAt)
+		}
+	}
+}
+
+func (s *Service) loadPlaylist(pl database.Playlist) error {
+	if err := s.AddPlaylist(pl.Id, pl.Name, pl.OwnerId); err != nil {
+		return err
+	}
+
+	if pl.ShareToken != "" {
+		s.addShare(pl.Id, pl.ShareToken)
+	}
+
+	if pl.WebhookUrl != "" {
+		s.setWebhook(pl.Id, pl.WebhookUrl)
+	}
+
+	if pl.Volume != nil {
+		s.setVolume(pl.Id, *pl.Volume)
+	}
+
+	if pl.Version > 0 {
+		s.setVersion(pl.Id, pl.Version)
+	}
+
+	return nil
+}
+
+func (s *Service) loadSong(sn database.Song) error {
+	if err := s.addSong(sn.PlaylistId, sn); err != nil {
+		return err
+	}
+
+	if pl, err := s.GetPlaylist(sn.PlaylistId); err == nil {
+		pl.SetSongTimes(sn.SongId, sn.CreatedAt, sn.UpdatedAt)
+		pl.SetPlayCount(sn.SongId, sn.PlayCount)
+	}
+
+	return nil
+}
+
+func (s *Service) Init(ctx context.Context) {
+	s.Start()
+
+	resumed := s.RestorePlaylists(ctx)
+
+	log.Printf("service | init | resume %t | resumed %d", s.config.ResumeOnStart, resumed)
+}
+
+func (s *Service) RestorePlaylists(ctx context.Context) int {
+	pls, err := s.db.LoadPlaylists()
+	if err != nil {
+		s.LogError("restore playlists", err)
+
+		return 0
+	}
+
+	resumed := 0
+
+	for _, dbpl := range pls {
+		if dbpl.CurrentSongId == 0 {
+			continue
+		}
+
+		pl, err := s.GetPlaylist(dbpl.Id)
+		if err != nil {
+			s.LogError("restore playlist", err, slog.Uint64("playlist_id", uint64(dbpl.Id)))
+
+			continue
+		}
+
+		launched := dbpl.State != string(playlist.StateStopped)
+		playing := dbpl.State == string(playlist.StatePlaying)
+
+		if err := pl.Restore(dbpl.CurrentSongId, dbpl.Elapsed, playing && s.config.ResumeOnStart); err != nil {
+			s.LogError("restore playlist", err, slog.Uint64("playlist_id", uint64(dbpl.Id)))
 
 			continue
 		}
+
+		if launched && s.config.ResumeOnStart {
+			if err := s.LaunchPlaylist(ctx, dbpl.Id); err != nil {
+				s.LogError("resume playlist", err, slog.Uint64("playlist_id", uint64(dbpl.Id)))
+
+				continue
+			}
+
+			resumed++
+		}
 	}
+
+	return resumed
 }
 
 func (s *Service) ForceStop(cancel context.CancelFunc) {
@@ -83,170 +337,1033 @@ func (s *Service) ForceStop(cancel context.CancelFunc) {
 	cancel()
 }
 
-func (s *Service) Stop(ctx context.Context) {
-	<-ctx.Done()
+func (s *Service) Shutdown(ctx context.Context) error {
+	log.Print("service | shutting down")
+
+	s.shuttingDown.Store(true)
+
+	s.mu.Lock()
+	for id, sc := range s.schedules {
+		sc.cancel()
+
+		delete(s.schedules, id)
+	}
+
+	for id, w := range s.workers {
+		if pl, ok := s.playlists[id]; ok {
+			s.savePlayback(pl)
+		}
+
+		w.cancel()
+	}
+	s.mu.Unlock()
+
+	chanDone := make(chan struct{})
+
+	go func() {
+		defer close(chanDone)
+		s.activeWg.Wait()
+	}()
+
+	select {
+	case <-chanDone:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	s.logMu.Lock()
+	s.logClosed = true
+	close(s.ChanErrorLog)
+	s.logMu.Unlock()
 
-	s.activeWg.Wait()
+	select {
+	case <-s.chanLogDone:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 
 	log.Print("service | stop")
+
+	return nil
 }
 
-func (s *Service) GetPlaylists() Playlists {
-	return s.playlists
+func (s *Service) Config() Config {
+	return s.config
 }
 
-func (s *Service) GetPlaylist(id uint) (*playlist.Playlist, error) {
-	if pl, ok := s.playlists[id]; ok {
-		return pl, nil
+func (s *Service) Logger() *slog.Logger {
+	return s.logger
+}
+
+func (s *Service) LogError(op string, err error, attrs ...any) {
+	if err == nil {
+		return
+	}
+
+	s.logger.Error("operation failed", append([]any{slog.String("op", op), slog.Any("error", err)}, attrs...)...)
+
+	s.logMu.RLock()
+	defer s.logMu.RUnlock()
+
+	if s.logClosed {
+		return
 	}
 
-	return nil, ErrNoPlaylistWithId
+	select {
+	case s.ChanErrorLog <- &OpError{Op: op, Err: err}:
+	default:
+		metrics.DroppedErrors.Inc()
+	}
 }
 
-func (s *Service) LaunchPlaylist(ctx context.Context, id uint) error {
-	pl, err := s.GetPlaylist(id)
+func (s *Service) TracerProvider() trace.TracerProvider {
+	return s.config.TracerProvider
+}
+
+func endSpan(span trace.Span, err error) {
 	if err != nil {
-		return err
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 	}
 
-	if pl.IsProcessing() {
-		return playlist.ErrAlreadyProcessing
-	}
+	span.End()
+}
 
-	s.activeWg.Add(1)
-	go func() {
-		defer s.activeWg.Done()
-		pl.Process(ctx)
-	}()
+func (s *Service) HealthCheck(ctx context.Context) (time.Duration, error) {
+	t := time.Now()
 
-	return nil
+	err := s.db.Ping(ctx)
+
+	return time.Since(t), err
 }
 
-func (s *Service) CreatePlaylist(dbpl *database.Playlist) error {
-	if err := s.db.CreatePlaylist(dbpl); err != nil {
-		return err
+func (s *Service) GetPlaylists() Playlists {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pls := make(Playlists, len(s.playlists))
+
+	for id, pl := range s.playlists {
+		pls[id] = pl
 	}
 
-	return s.AddPlaylist(dbpl.Id, dbpl.Name)
+	return pls
 }
 
-func (s *Service) AddPlaylist(id uint, name string) error {
-	if _, ok := s.playlists[id]; ok {
-		return ErrAlreadyExists
+func (s *Service) GetPlaylistsPage(offset, limit int) ([]*playlist.Playlist, int) {
+	pls := s.sortedPlaylists()
+
+	return Page(pls, offset, limit), len(pls)
+}
+
+func (s *Service) sortedPlaylists() []*playlist.Playlist {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]uint, 0, len(s.playlists))
+
+	for id := range s.playlists {
+		ids = append(ids, id)
 	}
 
-	pl := playlist.New(id, name)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
 
-	s.playlists[id] = pl
+	pls := make([]*playlist.Playlist, 0, len(ids))
 
-	return nil
+	for _, id := range ids {
+		pls = append(pls, s.playlists[id])
+	}
+
+	return pls
 }
 
-func (s *Service) EditPlaylist(id uint, name string) error {
-	pl, err := s.GetPlaylist(id)
+func (s *Service) GetPlaylistsForUser(userId string) []*playlist.Playlist {
+	return OwnedBy(s.sortedPlaylists(), userId)
+}
+
+func OwnedBy(pls []*playlist.Playlist, userId string) []*playlist.Playlist {
+	owned := make([]*playlist.Playlist, 0, len(pls))
+
+	for _, pl := range pls {
+		if pl.Owner == userId {
+			owned = append(owned, pl)
+		}
+	}
+
+	return owned
+}
+
+func (s *Service) SearchPlaylists(query string) ([]*playlist.Playlist, error) {
+	dbpls, err := s.db.SearchPlaylists(query)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if err := s.db.UpdatePlaylist(id, name); err != nil {
-		return err
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pls := make([]*playlist.Playlist, 0, len(dbpls))
+
+	for _, dbpl := range dbpls {
+		if pl, ok := s.playlists[dbpl.Id]; ok {
+			pls = append(pls, pl)
+		}
 	}
 
-	pl.Name = name
+	return pls, nil
+}
 
-	return nil
+func Page(pls []*playlist.Playlist, offset, limit int) []*playlist.Playlist {
+	if offset > len(pls) {
+		offset = len(pls)
+	}
+
+	end := offset + limit
+	if end > len(pls) {
+		end = len(pls)
+	}
+
+	return pls[offset:end]
+}
+
+func (s *Service) GetPlaylist(id uint) (*playlist.Playlist, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if pl, ok := s.playlists[id]; ok {
+		return pl, nil
+	}
+
+	return nil, ErrPlaylistNotFound
 }
 
-func (s *Service) DeletePlaylist(id uint) error {
+func (s *Service) LaunchPlaylist(ctx context.Context, id uint, links ...trace.Link) error {
 	pl, err := s.GetPlaylist(id)
 	if err != nil {
 		return err
 	}
 
-	if pl.IsProcessing() {
-		if err := pl.Stop(); err != nil {
-			return err
-		}
+	if pl.IsEmpty() {
+		return ErrNoSongs
 	}
 
-	if err := s.db.DeletePlaylist(id); err != nil {
-		return err
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.shuttingDown.Load() {
+		return ErrShuttingDown
 	}
 
-	for _, sn := range pl.GetSongsList() {
-		if err := s.db.DeleteSong(sn.Id); err != nil {
-			return err
-		}
+	if _, ok := s.workers[id]; ok || pl.IsProcessing() {
+		return ErrAlreadyLaunched
 	}
 
-	delete(s.playlists, id)
+	if s.config.MaxLaunches > 0 && len(s.workers) >= s.config.MaxLaunches {
+		return ErrLaunchLimit
+	}
+
+	workerCtx, cancel := context.WithCancel(ctx)
+
+	workerCtx, span := s.tracer.Start(workerCtx, "playlist.Process", trace.WithNewRoot(), trace.WithLinks(links...))
+	span.SetAttributes(attribute.Int64("playlist.id", int64(id)))
+
+	w := &worker{
+		ctx:     workerCtx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		started: time.Now().UTC(),
+	}
+
+	s.workers[id] = w
+
+	metrics.LaunchedPlaylists.Set(float64(len(s.workers)))
+
+	events, unsubscribe := pl.Subscribe()
+
+	s.activeWg.Add(3)
+	go func() {
+		defer s.activeWg.Done()
+		defer close(w.done)
+		defer s.releaseWorker(id, w)
+		defer span.End()
+
+		pl.Process(workerCtx)
+
+		if !s.shuttingDown.Load() {
+			s.savePlayback(pl)
+		}
+	}()
+
+	go func() {
+		defer s.activeWg.Done()
+
+		s.persistPlayback(workerCtx, pl)
+	}()
+
+	go func() {
+		defer s.activeWg.Done()
+		defer unsubscribe()
+
+		s.watchEvents(workerCtx, pl, events)
+	}()
 
 	return nil
 }
 
-func (s *Service) CreateSong(dbsn *database.Song) error {
-	if err := s.db.CreateSong(dbsn); err != nil {
-		return err
+func (s *Service) persistPlayback(ctx context.Context, pl *playlist.Playlist) {
+	ticker := time.NewTicker(s.config.PersistInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.savePlayback(pl)
+		}
+	}
+}
+
+func (s *Service) savePlayback(pl *playlist.Playlist) {
+	st := pl.Status()
+
+	if err := s.db.SavePlayback(st.Id, st.CurrentId, st.Time, string(st.State())); err != nil {
+		s.LogError("save playback", err, slog.Uint64("playlist_id", uint64(st.Id)))
 	}
 
-	return s.AddSong(dbsn.PlaylistId, dbsn.SongId, dbsn.Name, dbsn.Duration)
+	if plays := pl.TakePlays(); len(plays) > 0 {
+		if err := s.db.AddPlayCounts(plays); err != nil {
+			s.LogError("save play counts", err, slog.Uint64("playlist_id", uint64(st.Id)))
+		}
+	}
 }
 
-func (s *Service) AddSong(id uint, sid uint, name string, duration uint) error {
+func (s *Service) StopLaunch(id uint) error {
 	pl, err := s.GetPlaylist(id)
 	if err != nil {
 		return err
 	}
 
-	return pl.AddSong(sid, name, duration)
-}
+	s.mu.RLock()
+	w, ok := s.workers[id]
+	s.mu.RUnlock()
 
-func (s *Service) EditSong(id uint, sid uint, name string, duration uint) error {
-	pl, err := s.GetPlaylist(id)
-	if err != nil {
+	if !ok {
+		return playlist.ErrAlreadyStopped
+	}
+
+	if err := pl.Stop(); err != nil && !errors.Is(err, playlist.ErrAlreadyStopped) {
 		return err
 	}
 
-	if pl.IsCurrent(sid) && pl.IsProcessing() {
-		return playlist.ErrEditCurrent
+	w.cancel()
+
+	<-w.done
+
+	return nil
+}
+
+func (s *Service) RestartPlaylist(id uint) (playlist.Song, error) {
+	pl, err := s.GetPlaylist(id)
+	if err != nil {
+		return playlist.Song{}, err
 	}
 
-	sn, err := pl.GetSong(sid)
+	sn, err := pl.Restart()
 	if err != nil {
-		return err
+		return playlist.Song{}, err
 	}
 
-	if name == "" {
-		name = sn.Name
+	if !pl.IsProcessing() {
+		s.savePlayback(pl)
 	}
 
-	if duration == 0 {
-		duration = sn.Duration
+	return sn, nil
+}
+
+func (s *Service) releaseWorker(id uint, w *worker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w.cancel()
+
+	if s.workers[id] == w {
+		delete(s.workers, id)
 	}
 
-	if err := s.db.UpdateSong(sid, name, duration); err != nil {
-		return err
+	metrics.LaunchedPlaylists.Set(float64(len(s.workers)))
+}
+
+func (s *Service) checkName(id uint, name string) error {
+	if !s.config.UniqueNames {
+		return nil
 	}
 
-	sn.Name = name
-	sn.Duration = duration
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	if pl.IsCurrent(sid) {
-		return pl.SetTime(0)
+	for plid, pl := range s.playlists {
+		if plid != id && strings.EqualFold(norm.NFC.String(pl.Status().Name), name) {
+			return ErrDuplicateName
+		}
 	}
 
 	return nil
 }
 
-func (s *Service) DeleteSong(id uint, sid uint) error {
-	pl, err := s.GetPlaylist(id)
-	if err != nil {
+func NormalizeName(name string) (string, error) {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+
+		return r
+	}, name)
+
+	name = strings.TrimSpace(norm.NFC.String(name))
+
+	if name == "" || utf8.RuneCountInString(name) > MaxNameLength {
+		return "", ErrInvalidName
+	}
+
+	return name, nil
+}
+
+func (s *Service) CreatePlaylist(ctx context.Context, dbpl *database.Playlist) (err error) {
+	ctx, span := s.tracer.Start(ctx, "service.CreatePlaylist")
+	defer func() { endSpan(span, err) }()
+
+	if dbpl.Name, err = NormalizeName(dbpl.Name); err != nil {
+		return err
+	}
+
+	if err = s.checkName(0, dbpl.Name); err != nil {
+		return err
+	}
+
+	if claims, ok := auth.FromContext(ctx); ok {
+		dbpl.OwnerId = claims.UserId
+	}
+
+	dbctx, dbspan := s.tracer.Start(ctx, "database.CreatePlaylist")
+	err = s.db.CreatePlaylist(dbctx, dbpl)
+	endSpan(dbspan, err)
+
+	if err != nil {
+		return err
+	}
+
+	return s.AddPlaylist(dbpl.Id, dbpl.Name, dbpl.OwnerId)
+}
+
+func (s *Service) CreatePlaylistWithSongs(ctx context.Context, dbpl *database.Playlist, dbsns []database.Song) (err error) {
+	ctx, span := s.tracer.Start(ctx, "service.CreatePlaylistWithSongs")
+	defer func() { endSpan(span, err) }()
+
+	if dbpl.Name, err = NormalizeName(dbpl.Name); err != nil {
+		return err
+	}
+
+	if err = ValidateSongs(dbsns); err != nil {
+		return err
+	}
+
+	if err = s.checkName(0, dbpl.Name); err != nil {
+		return err
+	}
+
+	if claims, ok := auth.FromContext(ctx); ok {
+		dbpl.OwnerId = claims.UserId
+	}
+
+	dbctx, dbspan := s.tracer.Start(ctx, "database.CreatePlaylistWithSongs")
+	err = s.db.CreatePlaylistWithSongs(dbctx, dbpl, dbsns)
+	endSpan(dbspan, err)
+
+	if err != nil {
+		return err
+	}
+
+	if err = s.AddPlaylist(dbpl.Id, dbpl.Name, dbpl.OwnerId); err != nil {
+		return err
+	}
+
+	for _, sn := range dbsns {
+		if err = s.addSong(dbpl.Id, sn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) ClonePlaylist(ctx context.Context, id uint, name string) (*database.Playlist, error) {
+	pl, err := s.GetPlaylist(id)
+	if err != nil {
+		return nil, err
+	}
+
+	base, copies := pl.Status().Name, 0
+
+	if name == "" {
+		if name, copies, err = s.copyName(base, 1); err != nil {
+			return nil, err
+		}
+	} else if name, err = NormalizeName(name); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkName(0, name); err != nil {
+		return nil, err
+	}
+
+	dbpl := &database.Playlist{Name: name}
+
+	if claims, ok := auth.FromContext(ctx); ok {
+		dbpl.OwnerId = claims.UserId
+	}
+
+	var dbsns []database.Song
+
+	for _, sn := range pl.GetSongsList() {
+		dbsns = append(dbsns, database.Song{Name: sn.Name, Duration: database.Duration(sn.Duration), Tags: sn.Tags, Favorite: sn.Favorite})
+	}
+
+	for {
+		err = s.db.CreatePlaylistWithSongs(ctx, dbpl, dbsns)
+		if copies == 0 || !errors.Is(err, ErrDuplicateName) {
+			break
+		}
+
+		dbpl.Id = 0
+
+		if dbpl.Name, copies, err = s.copyName(base, copies+1); err != nil {
+			return nil, err
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.AddPlaylist(dbpl.Id, dbpl.Name, dbpl.OwnerId); err != nil {
+		return nil, err
+	}
+
+	for _, sn := range dbsns {
+		if err := s.addSong(dbpl.Id, sn); err != nil {
+			return nil, err
+		}
+	}
+
+	return dbpl, nil
+}
+
+func (s *Service) copyName(name string, from int) (string, int, error) {
+	for n := from; n <= maxCopyNames; n++ {
+		suffix := " (copy)"
+		if n > 1 {
+			suffix = fmt.Sprintf(" (copy %d)", n)
+		}
+
+		runes := []rune(name)
+		if limit := MaxNameLength - utf8.RuneCountInString(suffix); len(runes) > limit {
+			runes = runes[:limit]
+		}
+
+		candidate := strings.TrimSpace(string(runes)) + suffix
+
+		if s.checkName(0, candidate) == nil {
+			return candidate, n, nil
+		}
+	}
+
+	return "", 0, ErrDuplicateName
+}
+
+func (s *Service) AddPlaylist(id uint, name string, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.playlists[id]; ok {
+		return ErrAlreadyExists
+	}
+
+	pl := playlist.New(id, name)
+	pl.Owner = owner
+	pl.SetHistorySize(s.config.HistorySize)
+	pl.SetTickInterval(s.config.TickInterval)
+
+	s.playlists[id] = pl
+
+	metrics.Playlists.Set(float64(len(s.playlists)))
+
+	return nil
+}
+
+func (s *Service) EditPlaylist(ctx context.Context, id uint, name string) error {
+	pl, err := s.GetPlaylist(id)
+	if err != nil {
+		return err
+	}
+
+	if name, err = NormalizeName(name); err != nil {
+		return err
+	}
+
+	if err := s.checkName(id, name); err != nil {
+		return err
+	}
+
+	if err := s.db.UpdatePlaylist(ctx, id, name); err != nil {
+		return err
+	}
+
+	pl.SetName(name)
+	pl.BumpVersion()
+
+	return nil
+}
+
+func (s *Service) DeletePlaylist(ctx context.Context, id uint) error {
+	if s.config.SoftDelete {
+		return s.removePlaylist(ctx, id, s.db.SoftDeletePlaylist)
+	}
+
+	return s.removePlaylist(ctx, id, s.db.PurgePlaylist)
+}
+
+func (s *Service) removePlaylist(ctx context.Context, id uint, remove func(context.Context, uint) error) error {
+	pl, err := s.GetPlaylist(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.StopLaunch(id); err != nil && !errors.Is(err, playlist.ErrAlreadyStopped) {
+		return err
+	}
+
+	if err := remove(ctx, id); err != nil {
+		return err
+	}
+
+	s.removeShares(id)
+	s.removeEditLock(id)
+
+	s.mu.Lock()
+	if sc, ok := s.schedules[id]; ok {
+		sc.cancel()
+
+		delete(s.schedules, id)
+	}
+
+	delete(s.webhooks, id)
+	delete(s.playlists, id)
+	metrics.Playlists.Set(float64(len(s.playlists)))
+	s.mu.Unlock()
+
+	pl.Close()
+
+	return nil
+}
+
+func ValidateDuration(duration uint) error {
+	if duration < 1 || duration > MaxSongDuration {
+		return ErrInvalidDuration
+	}
+
+	return nil
+}
+
+func ValidateSongs(dbsns []database.Song) error {
+	for i, sn := range dbsns {
+		if err := ValidateDuration(uint(sn.Duration)); err != nil {
+			return &database.SongError{Index: i, Err: err}
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) CreateSong(ctx context.Context, dbsn *database.Song) (err error) {
+	ctx, span := s.tracer.Start(ctx, "service.CreateSong")
+	defer func() { endSpan(span, err) }()
+
+	if err = ValidateDuration(uint(dbsn.Duration)); err != nil {
+		return err
+	}
+
+	dbctx, dbspan := s.tracer.Start(ctx, "database.CreateSong")
+	err = s.db.CreateSong(dbctx, dbsn)
+	endSpan(dbspan, err)
+
+	if err != nil {
+		return err
+	}
+
+	if err = s.addSong(dbsn.PlaylistId, *dbsn); err != nil {
+		return err
+	}
+
+	s.bumpVersion(dbsn.PlaylistId)
+
+	return nil
+}
+
+func (s *Service) InsertSong(ctx context.Context, plId uint, dbsn *database.Song, pos int) (err error) {
+	ctx, span := s.tracer.Start(ctx, "service.InsertSong")
+	defer func() { endSpan(span, err) }()
+
+	if err = ValidateDuration(uint(dbsn.Duration)); err != nil {
+		return err
+	}
+
+	pl, err := s.GetPlaylist(plId)
+	if err != nil {
+		return err
+	}
+
+	if count := len(pl.GetSongsList()); pos < 0 || pos > count {
+		pos = count
+	}
+
+	dbsn.PlaylistId = plId
+
+	dbctx, dbspan := s.tracer.Start(ctx, "database.InsertSong")
+	err = s.db.InsertSong(dbctx, dbsn, pos)
+	endSpan(dbspan, err)
+
+	if err != nil {
+		return err
+	}
+
+	if err = pl.InsertSong(dbsn.SongId, dbsn.Name, uint(dbsn.Duration), pos); err != nil {
+		return err
+	}
+
+	pl.BumpVersion()
+
+	return pl.SetSongTags(dbsn.SongId, dbsn.Tags)
+}
+
+func (s *Service) AddSongs(ctx context.Context, plId uint, dbsns []database.Song, pos int) (err error) {
+	ctx, span := s.tracer.Start(ctx, "service.AddSongs")
+	defer func() { endSpan(span, err) }()
+
+	if err = ValidateSongs(dbsns); err != nil {
+		return err
+	}
+
+	pl, err := s.GetPlaylist(plId)
+	if err != nil {
+		return err
+	}
+
+	if count := len(pl.GetSongsList()); pos < 0 || pos > count {
+		pos = count
+	}
+
+	dbctx, dbspan := s.tracer.Start(ctx, "database.InsertSongs")
+	err = s.db.InsertSongs(dbctx, plId, dbsns, pos)
+	endSpan(dbspan, err)
+
+	if err != nil {
+		return err
+	}
+
+	for i, sn := range dbsns {
+		if err = pl.InsertSong(sn.SongId, sn.Name, uint(sn.Duration), pos+i); err != nil {
+			return err
+		}
+
+		if err = pl.SetSongTags(sn.SongId, sn.Tags); err != nil {
+			return err
+		}
+	}
+
+	pl.BumpVersion()
+
+	return nil
+}
+
+func (s *Service) AddSong(id uint, sid uint, name string, duration uint) error {
+	pl, err := s.GetPlaylist(id)
+	if err != nil {
+		return err
+	}
+
+	return pl.AddSong(sid, name, duration)
+}
+
+func (s *Service) addSong(id uint, sn database.Song) error {
+	if err := s.AddSong(id, sn.SongId, sn.Name, uint(sn.Duration)); err != nil {
+		return err
+	}
+
+	if len(sn.Tags) == 0 && !sn.Favorite {
+		return nil
+	}
+
+	pl, err := s.GetPlaylist(id)
+	if err != nil {
+		return err
+	}
+
+	if err := pl.SetSongTags(sn.SongId, sn.Tags); err != nil {
+		return err
+	}
+
+	return pl.SetFavorite(sn.SongId, sn.Favorite)
+}
+
+func (s *Service) EditSong(ctx context.Context, id uint, sid uint, name string, duration uint, tags []string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "service.EditSong")
+	defer func() { endSpan(span, err) }()
+
+	if duration != 0 {
+		if err = ValidateDuration(duration); err != nil {
+			return err
+		}
+	}
+
+	pl, err := s.GetPlaylist(id)
+	if err != nil {
+		return err
+	}
+
+	if pl.IsCurrent(sid) && pl.IsProcessing() {
+		return playlist.ErrEditCurrent
+	}
+
+	sn, err := pl.GetSong(sid)
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		name = sn.Name
+	}
+
+	if duration == 0 {
+		duration = sn.Duration
+	}
+
+	if tags == nil {
+		tags = sn.Tags
+	} else {
+		tags = database.NormalizeTags(tags)
+	}
+
+	dbctx, dbspan := s.tracer.Start(ctx, "database.UpdateSong")
+	err = s.db.UpdateSong(dbctx, id, sid, name, duration, tags)
+	endSpan(dbspan, err)
+
+	if err != nil {
+		return err
+	}
+
+	if err = pl.EditSong(sid, name, duration); err != nil {
+		return err
+	}
+
+	pl.BumpVersion()
+
+	return pl.SetSongTags(sid, tags)
+}
+
+func (s *Service) DeleteSong(ctx context.Context, id uint, sid uint) error {
+	pl, err := s.GetPlaylist(id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := pl.GetSong(sid); err != nil {
+		return err
+	}
+
+	if pl.IsCurrent(sid) && pl.IsPlaying() {
+		return playlist.ErrRemovePlaying
+	}
+
+	if err := s.db.DeleteSong(ctx, id, sid); err != nil {
+		return err
+	}
+
+	if err := pl.Remove(sid); err != nil {
+		return err
+	}
+
+	pl.BumpVersion()
+
+	return nil
+}
+
+func (s *Service) MoveSong(ctx context.Context, id uint, sid uint, position int) error {
+	pl, err := s.GetPlaylist(id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := pl.GetSong(sid); err != nil {
+		return err
+	}
+
+	var ids []uint
+
+	for _, sn := range pl.GetSongsList() {
+		if sn.Id != sid {
+			ids = append(ids, sn.Id)
+		}
+	}
+
+	if position < 0 {
+		position = 0
+	}
+
+	if position > len(ids) {
+		position = len(ids)
+	}
+
+	ids = append(ids[:position], append([]uint{sid}, ids[position:]...)...)
+
+	if err := s.db.UpdateSongPositions(ctx, id, ids); err != nil {
+		return err
+	}
+
+	if err := pl.MoveSong(sid, position); err != nil {
+		return err
+	}
+
+	pl.BumpVersion()
+
+	return nil
+}
+
+func (s *Service) SwapSongs(ctx context.Context, id uint, a uint, b uint) error {
+	if a == b {
+		return playlist.ErrSwapSame
+	}
+
+	pl, err := s.GetPlaylist(id)
+	if err != nil {
+		return err
+	}
+
+	var ids []uint
+
+	found := 0
+
+	for _, sn := range pl.GetSongsList() {
+		sid := sn.Id
+
+		switch sid {
+		case a:
+			sid = b
+			found++
+		case b:
+			sid = a
+			found++
+		}
+
+		ids = append(ids, sid)
+	}
+
+	if found != 2 {
+		return playlist.ErrSongNotIn
+	}
+
+	if err := s.db.UpdateSongPositions(ctx, id, ids); err != nil {
+		return err
+	}
+
+	if err := pl.SwapSongs(a, b); err != nil {
+		return err
+	}
+
+	pl.BumpVersion()
+
+	return nil
+}
+
+func (s *Service) ReplaceSongs(ctx context.Context, id uint, dbsns []database.Song) error {
+	pl, err := s.GetPlaylist(id)
+	if err != nil {
+		return err
+	}
+
+	if pl.IsProcessing() {
+		return ErrPlaylistLaunched
+	}
+
+	if err := ValidateSongs(dbsns); err != nil {
+		return err
+	}
+
+	if err := s.db.ReplaceSongs(ctx, id, dbsns); err != nil {
+		return err
+	}
+
+	pl.Clear()
+
+	for _, sn := range dbsns {
+		if err := s.addSong(id, sn); err != nil {
+			return err
+		}
+	}
+
+	pl.BumpVersion()
+
+	return nil
+}
+
+func (s *Service) TransferSong(ctx context.Context, id uint, sid uint, target uint) error {
+	if id == target {
+		return ErrSameTarget
+	}
+
+	from, err := s.GetPlaylist(id)
+	if err != nil {
+		return err
+	}
+
+	to, err := s.GetPlaylist(target)
+	if err != nil {
+		return err
+	}
+
+	sn, err := from.GetSong(sid)
+	if err != nil {
+		return err
+	}
+
+	if from.IsPlaying() && from.IsCurrent(sid) {
+		return playlist.ErrRemovePlaying
+	}
+
+	name, duration, count, tags, favorite := sn.Name, sn.Duration, sn.PlayCount, sn.Tags, sn.Favorite
+
+	if err := s.db.TransferSong(ctx, id, sid, target); err != nil {
+		return err
+	}
+
+	from.BumpVersion()
+	to.BumpVersion()
+
+	if err := from.Remove(sid); err != nil {
+		return err
+	}
+
+	if err := to.AddSong(sid, name, duration); err != nil {
+		return err
+	}
+
+	if err := to.SetPlayCount(sid, count); err != nil {
 		return err
 	}
 
-	if err := s.db.DeleteSong(sid); err != nil {
+	if err := to.SetSongTags(sid, tags); err != nil {
 		return err
 	}
 
-	return pl.Remove(sid)
+	return to.SetFavorite(sid, favorite)
 }
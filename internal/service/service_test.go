@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"gocloudcamp_test/internal/database"
+	"gocloudcamp_test/internal/database/dbtest"
+	"gocloudcamp_test/internal/playlist"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+
+	os.Exit(m.Run())
+}
+
+func newTestService(t *testing.T, config Config) (*Service, *dbtest.Store) {
+	t.Helper()
+
+	db, store := dbtest.Open(t)
+
+	return New(db, config), store
+}
+
+func createTestPlaylist(t *testing.T, s *Service, name string, durations ...uint) *playlist.Playlist {
+	t.Helper()
+
+	dbsns := make([]database.Song, len(durations))
+
+	for i, duration := range durations {
+		dbsns[i] = database.Song{Name: "song", Duration: database.Duration(duration)}
+	}
+
+	dbpl := &database.Playlist{Name: name}
+
+	if err := s.CreatePlaylistWithSongs(context.Background(), dbpl, dbsns); err != nil {
+		t.Fatalf("create playlist: %v", err)
+	}
+
+	pl, err := s.GetPlaylist(dbpl.Id)
+	if err != nil {
+		t.Fatalf("get playlist: %v", err)
+	}
+
+	return pl
+}
+
+func songIds(pl *playlist.Playlist) []uint {
+	var ids []uint
+
+	for _, sn := range pl.GetSongsList() {
+		ids = append(ids, sn.Id)
+	}
+
+	return ids
+}
+
+func launchTestPlaylist(t *testing.T, s *Service, pl *playlist.Playlist) {
+	t.Helper()
+
+	if err := s.LaunchPlaylist(context.Background(), pl.Id); err != nil {
+		t.Fatalf("launch playlist %d: %v", pl.Id, err)
+	}
+
+	for !pl.IsProcessing() {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestConfigDefaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		field  func(Config) time.Duration
+		want   time.Duration
+	}{
+		{"persist interval unset", Config{}, func(c Config) time.Duration { return c.PersistInterval }, time.Second * 5},
+		{"persist interval negative", Config{PersistInterval: -time.Second}, func(c Config) time.Duration { return c.PersistInterval }, time.Second * 5},
+		{"persist interval set", Config{PersistInterval: time.Minute}, func(c Config) time.Duration { return c.PersistInterval }, time.Minute},
+		{"progress interval unset", Config{}, func(c Config) time.Duration { return c.ProgressInterval }, time.Second},
+		{"progress interval negative", Config{ProgressInterval: -time.Second}, func(c Config) time.Duration { return c.ProgressInterval }, time.Second},
+		{"progress interval set", Config{ProgressInterval: time.Minute}, func(c Config) time.Duration { return c.ProgressInterval }, time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestService(t, tt.config)
+
+			if got := tt.field(s.Config()); got != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
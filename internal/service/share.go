@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+
+	"gocloudcamp_test/internal/playlist"
+)
+
+var (
+	ErrNotShared     = errors.New("playlist is not shared")
+	ErrShareNotFound = errors.New("there is no shared playlist with such token")
+)
+
+func newShareToken() (string, error) {
+	b := make([]byte, 24)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func (s *Service) addShare(id uint, token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for t, plid := range s.shares {
+		if plid == id {
+			delete(s.shares, t)
+		}
+	}
+
+	if token != "" {
+		s.shares[token] = id
+	}
+}
+
+func (s *Service) removeShares(id uint) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := false
+
+	for t, plid := range s.shares {
+		if plid == id {
+			delete(s.shares, t)
+
+			found = true
+		}
+	}
+
+	return found
+}
+
+func (s *Service) SharePlaylist(ctx context.Context, id uint) (string, error) {
+	if _, err := s.GetPlaylist(id); err != nil {
+		return "", err
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.db.SetShareToken(ctx, id, token); err != nil {
+		return "", err
+	}
+
+	s.addShare(id, token)
+
+	return token, nil
+}
+
+func (s *Service) UnsharePlaylist(ctx context.Context, id uint) error {
+	if _, err := s.GetPlaylist(id); err != nil {
+		return err
+	}
+
+	if !s.removeShares(id) {
+		return ErrNotShared
+	}
+
+	return s.db.SetShareToken(ctx, id, "")
+}
+
+func (s *Service) GetSharedPlaylist(token string) (*playlist.Playlist, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if id, ok := s.shares[token]; ok {
+		if pl, ok := s.playlists[id]; ok {
+			return pl, nil
+		}
+	}
+
+	return nil, ErrShareNotFound
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"log/slog"
+	"time"
+
+	"gocloudcamp_test/internal/playlist"
+)
+
+const MaxSleepMinutes = 1440
+
+var ErrInvalidSleep = errors.New("sleep timer must be between 0 and 1440 minutes")
+
+func (s *Service) SleepPlaylist(id uint, minutes uint) (time.Time, error) {
+	if minutes > MaxSleepMinutes {
+		return time.Time{}, ErrInvalidSleep
+	}
+
+	if _, err := s.GetPlaylist(id); err != nil {
+		return time.Time{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, ok := s.workers[id]
+	if !ok {
+		return time.Time{}, playlist.ErrNotProcessed
+	}
+
+	if w.sleep != nil {
+		w.sleep()
+		w.sleep = nil
+
+		log.Printf("service | sleep cancelled | id %d", id)
+	}
+
+	if minutes == 0 {
+		return time.Time{}, nil
+	}
+
+	d := time.Duration(minutes) * time.Minute
+	at := time.Now().Add(d).UTC().Truncate(time.Second)
+
+	ctx, cancel := context.WithCancel(w.ctx)
+	w.sleep = cancel
+
+	go s.sleepStop(ctx, id, w, d)
+
+	log.Printf("service | sleep | id %d | at %s", id, at.Format(time.RFC3339))
+
+	return at, nil
+}
+
+func (s *Service) sleepStop(ctx context.Context, id uint, w *worker, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return
+	case <-timer.C:
+	}
+
+	s.mu.RLock()
+	current := s.workers[id] == w
+	s.mu.RUnlock()
+
+	if !current {
+		return
+	}
+
+	log.Printf("service | sleep fired | id %d", id)
+
+	if err := s.StopLaunch(id); err != nil && !errors.Is(err, playlist.ErrAlreadyStopped) {
+		s.LogError("sleep stop", err, slog.Uint64("playlist_id", uint64(id)))
+	}
+}
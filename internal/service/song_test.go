@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"gocloudcamp_test/internal/database"
+)
+
+func TestValidateDuration(t *testing.T) {
+	tests := []struct {
+		duration uint
+		err      error
+	}{
+		{0, ErrInvalidDuration},
+		{1, nil},
+		{180, nil},
+		{MaxSongDuration, nil},
+		{MaxSongDuration + 1, ErrInvalidDuration},
+		{1 << 32, ErrInvalidDuration},
+		{^uint(0), ErrInvalidDuration},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.duration), func(t *testing.T) {
+			if err := ValidateDuration(tt.duration); !errors.Is(err, tt.err) {
+				t.Fatalf("err %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestInvalidDurationSkipsDatabase(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(s *Service, id uint, sid uint) error
+	}{
+		{"create", func(s *Service, id uint, _ uint) error {
+			return s.CreateSong(context.Background(), &database.Song{PlaylistId: id, Duration: 0})
+		}},
+		{"insert", func(s *Service, id uint, _ uint) error {
+			return s.InsertSong(context.Background(), id, &database.Song{Duration: MaxSongDuration + 1}, 0)
+		}},
+		{"edit", func(s *Service, id uint, sid uint) error {
+			return s.EditSong(context.Background(), id, sid, "", MaxSongDuration+1, nil)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, store := newTestService(t, Config{})
+			pl := createTestPlaylist(t, s, "duration", 60)
+
+			store.Reset()
+
+			if err := tt.call(s, pl.Id, songIds(pl)[0]); !errors.Is(err, ErrInvalidDuration) {
+				t.Fatalf("err %v, want %v", err, ErrInvalidDuration)
+			}
+
+			if queries := store.Queries(); len(queries) != 0 {
+				t.Fatalf("invalid duration reached the database: %q", queries)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"gocloudcamp_test/internal/playlist"
+)
+
+type Stats struct {
+	Playlists     int64                  `json:"playlists" xml:"playlists"`
+	Songs         int64                  `json:"songs" xml:"songs"`
+	TotalDuration uint64                 `json:"total_duration" xml:"total_duration"`
+	ByStatus      map[playlist.State]int `json:"by_status" xml:"-"`
+	Launched      int                    `json:"launched" xml:"launched"`
+	MaxLaunches   int                    `json:"max_launches,omitempty" xml:"max_launches,omitempty"`
+}
+
+func (s *Service) Stats(ctx context.Context, owner string, restricted bool) (*Stats, error) {
+	dbst, err := s.db.Stats(ctx, owner, restricted)
+	if err != nil {
+		return nil, err
+	}
+
+	st := &Stats{
+		Playlists:     dbst.Playlists,
+		Songs:         dbst.Songs,
+		TotalDuration: dbst.TotalDuration,
+		ByStatus: map[playlist.State]int{
+			playlist.StatePlaying: 0,
+			playlist.StatePaused:  0,
+			playlist.StateStopped: 0,
+		},
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, pl := range s.playlists {
+		if restricted && pl.Owner != owner {
+			continue
+		}
+
+		st.ByStatus[pl.Status().State()]++
+	}
+
+	st.Launched = len(s.workers)
+	st.MaxLaunches = s.config.MaxLaunches
+
+	return st, nil
+}
+
+func (s *Service) TopSongs(id uint, limit int) ([]playlist.Song, error) {
+	pl, err := s.GetPlaylist(id)
+	if err != nil {
+		return nil, err
+	}
+
+	sns := pl.GetSongsList()
+
+	sort.SliceStable(sns, func(i, j int) bool { return sns[i].PlayCount > sns[j].PlayCount })
+
+	if limit < len(sns) {
+		sns = sns[:limit]
+	}
+
+	return sns, nil
+}
+
+func (s *Service) History(id uint, limit int) ([]playlist.HistoryEntry, error) {
+	pl, err := s.GetPlaylist(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return pl.History(limit), nil
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"log/slog"
+
+	"gocloudcamp_test/internal/playlist"
+)
+
+type SyncMessage struct {
+	Origin     string         `json:"origin"`
+	PlaylistId uint           `json:"playlist_id"`
+	Event      playlist.Event `json:"event"`
+	SongId     uint           `json:"song_id,omitempty"`
+	Time       uint           `json:"time,omitempty"`
+}
+
+type Syncer interface {
+	Publish(ctx context.Context, msg SyncMessage) error
+	Subscribe(ctx context.Context, handler func(SyncMessage)) error
+}
+
+type suppressKey struct {
+	id uint
+	ev playlist.Event
+}
+
+func newNodeId() string {
+	b := make([]byte, 8)
+
+	if _, err := rand.Read(b); err != nil {
+		return "node"
+	}
+
+	return hex.EncodeToString(b)
+}
+
+func (s *Service) RunSync(ctx context.Context) {
+	if s.config.Syncer == nil {
+		return
+	}
+
+	log.Printf("service | sync | node %s", s.config.NodeId)
+
+	if err := s.config.Syncer.Subscribe(ctx, func(msg SyncMessage) { s.applySync(ctx, msg) }); err != nil && ctx.Err() == nil {
+		s.LogError("sync subscribe", err)
+	}
+}
+
+func (s *Service) syncEvent(pl *playlist.Playlist, ev playlist.Event) {
+	if s.config.Syncer == nil || ev == playlist.EventSwitch {
+		return
+	}
+
+	if s.consumeSuppressed(pl.Id, ev) {
+		return
+	}
+
+	st := pl.Status()
+
+	err := s.config.Syncer.Publish(context.Background(), SyncMessage{
+		Origin:     s.config.NodeId,
+		PlaylistId: st.Id,
+		Event:      ev,
+		SongId:     st.CurrentId,
+		Time:       st.Time,
+	})
+	if err != nil {
+		s.LogError("sync publish", err, slog.Uint64("playlist_id", uint64(st.Id)), slog.String("event", string(ev)))
+	}
+}
+
+func (s *Service) applySync(ctx context.Context, msg SyncMessage) {
+	if msg.Origin == s.config.NodeId {
+		return
+	}
+
+	pl, err := s.GetPlaylist(msg.PlaylistId)
+	if err != nil {
+		return
+	}
+
+	log.Printf("service | sync apply | id %d | event %s | origin %s", msg.PlaylistId, msg.Event, msg.Origin)
+
+	var applied playlist.Event
+
+	switch msg.Event {
+	case playlist.EventLaunch:
+		applied = playlist.EventLaunch
+		s.suppress(msg.PlaylistId, applied)
+		err = s.LaunchPlaylist(ctx, msg.PlaylistId)
+	case playlist.EventStop:
+		applied = playlist.EventStop
+		s.suppress(msg.PlaylistId, applied)
+		err = s.StopLaunch(msg.PlaylistId)
+	case playlist.EventPlay:
+		applied = playlist.EventPlay
+		s.suppress(msg.PlaylistId, applied)
+		err = pl.Play()
+	case playlist.EventPause:
+		applied = playlist.EventPause
+		s.suppress(msg.PlaylistId, applied)
+		err = pl.Pause()
+	case playlist.EventNext, playlist.EventPrev, playlist.EventJump, playlist.EventRestart:
+		applied = playlist.EventJump
+		s.suppress(msg.PlaylistId, applied)
+		err = pl.Sync(msg.SongId, msg.Time)
+	default:
+		return
+	}
+
+	if err != nil {
+		s.consumeSuppressed(msg.PlaylistId, applied)
+
+		log.Printf("service | sync skip | id %d | event %s | %v", msg.PlaylistId, msg.Event, err)
+	}
+}
+
+func (s *Service) suppress(id uint, ev playlist.Event) {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	s.suppressed[suppressKey{id, ev}]++
+}
+
+func (s *Service) consumeSuppressed(id uint, ev playlist.Event) bool {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	key := suppressKey{id, ev}
+
+	if s.suppressed[key] == 0 {
+		return false
+	}
+
+	s.suppressed[key]--
+
+	if s.suppressed[key] == 0 {
+		delete(s.suppressed, key)
+	}
+
+	return true
+}
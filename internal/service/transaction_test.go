@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gocloudcamp_test/internal/database"
+	"gocloudcamp_test/internal/database/dbtest"
+)
+
+var errSongInsert = errors.New("song insert failed")
+
+func failOnSongInsert(store *dbtest.Store, n int) {
+	inserts := 0
+
+	store.Fail(func(query string) error {
+		if !strings.HasPrefix(query, `INSERT INTO "songs"`) {
+			return nil
+		}
+
+		if inserts++; inserts == n {
+			return errSongInsert
+		}
+
+		return nil
+	})
+}
+
+func testSongs(n int) []database.Song {
+	dbsns := make([]database.Song, n)
+
+	for i := range dbsns {
+		dbsns[i] = database.Song{Name: "song", Duration: 60}
+	}
+
+	return dbsns
+}
+
+func checkRolledBack(t *testing.T, store *dbtest.Store) {
+	t.Helper()
+
+	queries := store.Queries()
+
+	if len(queries) == 0 || queries[0] != "BEGIN" || queries[len(queries)-1] != "ROLLBACK" {
+		t.Fatalf("queries %q, want a rolled back transaction", queries)
+	}
+
+	for _, query := range queries {
+		if query == "COMMIT" {
+			t.Fatalf("queries %q, want no commit", queries)
+		}
+	}
+}
+
+func checkSongError(t *testing.T, err error, index int) {
+	t.Helper()
+
+	var songErr *database.SongError
+
+	if !errors.As(err, &songErr) {
+		t.Fatalf("err %v, want a song error", err)
+	}
+
+	if songErr.Index != index || !errors.Is(err, errSongInsert) {
+		t.Fatalf("err %v, want song %d failing with %v", err, index, errSongInsert)
+	}
+}
+
+func TestCreatePlaylistWithSongsRollback(t *testing.T) {
+	s, store := newTestService(t, Config{})
+
+	store.Reset()
+	failOnSongInsert(store, 3)
+
+	dbpl := &database.Playlist{Name: "partial"}
+
+	err := s.CreatePlaylistWithSongs(context.Background(), dbpl, testSongs(4))
+
+	checkSongError(t, err, 2)
+	checkRolledBack(t, store)
+
+	if n := len(s.GetPlaylists()); n != 0 {
+		t.Fatalf("%d playlists after rollback, want 0", n)
+	}
+}
+
+func TestAddSongsRollback(t *testing.T) {
+	s, store := newTestService(t, Config{})
+	pl := createTestPlaylist(t, s, "partial", 60)
+
+	before, version := songIds(pl), pl.Version()
+
+	store.Reset()
+	failOnSongInsert(store, 3)
+
+	err := s.AddSongs(context.Background(), pl.Id, testSongs(4), -1)
+
+	checkSongError(t, err, 2)
+	checkRolledBack(t, store)
+
+	if after := songIds(pl); !reflect.DeepEqual(after, before) {
+		t.Fatalf("songs %v after rollback, want %v", after, before)
+	}
+
+	if pl.Version() != version {
+		t.Fatalf("version %d after rollback, want %d", pl.Version(), version)
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"gocloudcamp_test/internal/database"
+)
+
+func (s *Service) RestoreDeleted(ctx context.Context, id uint, owner string, restricted bool) error {
+	var since time.Time
+
+	if s.config.DeleteRetention > 0 {
+		since = time.Now().Add(-s.config.DeleteRetention)
+	}
+
+	dbpl, err := s.db.DeletedPlaylist(ctx, id, since)
+	if errors.Is(err, database.ErrNotFound) || (err == nil && restricted && dbpl.OwnerId != owner) {
+		return ErrPlaylistNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkName(0, dbpl.Name); err != nil {
+		return err
+	}
+
+	sns, err := s.db.LoadPlaylistSongs(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.RestorePlaylist(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.loadPlaylist(dbpl); err != nil {
+		return err
+	}
+
+	for _, sn := range sns {
+		if err := s.loadSong(sn); err != nil {
+			s.LogError("restore song", err, slog.Uint64("playlist_id", uint64(id)), slog.Uint64("song_id", uint64(sn.SongId)))
+		}
+	}
+
+	if pl, err := s.GetPlaylist(id); err == nil {
+		pl.SetTimes(dbpl.CreatedAt, dbpl.UpdatedAt)
+	}
+
+	return nil
+}
+
+func (s *Service) PurgePlaylist(ctx context.Context, id uint) error {
+	if _, err := s.GetPlaylist(id); err == nil {
+		return s.removePlaylist(ctx, id, s.db.PurgePlaylist)
+	}
+
+	if _, err := s.db.DeletedPlaylist(ctx, id, time.Time{}); err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return ErrPlaylistNotFound
+		}
+
+		return err
+	}
+
+	return s.db.PurgePlaylist(ctx, id)
+}
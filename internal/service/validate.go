@@ -0,0 +1,80 @@
+package service
+
+import (
+	"fmt"
+
+	"gocloudcamp_test/internal/database"
+)
+
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+type ValidationError struct {
+	Fields []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Fields) == 1 {
+		return e.Fields[0].Error()
+	}
+
+	return fmt.Sprintf("%v (and %d more)", e.Fields[0], len(e.Fields)-1)
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Fields))
+
+	for _, fe := range e.Fields {
+		errs = append(errs, fe)
+	}
+
+	return errs
+}
+
+func validatePlaylist(prefix string, name string, dbsns []database.Song) []*FieldError {
+	var fields []*FieldError
+
+	if _, err := NormalizeName(name); err != nil {
+		fields = append(fields, &FieldError{Field: prefix + "name", Err: err})
+	}
+
+	for i, sn := range dbsns {
+		if err := ValidateDuration(uint(sn.Duration)); err != nil {
+			fields = append(fields, &FieldError{Field: fmt.Sprintf("%ssongs[%d].duration", prefix, i), Err: err})
+		}
+	}
+
+	return fields
+}
+
+func ValidatePlaylist(name string, dbsns []database.Song) error {
+	if fields := validatePlaylist("", name, dbsns); len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+
+	return nil
+}
+
+func ValidatePlaylists(inputs []PlaylistInput) error {
+	var fields []*FieldError
+
+	for i, in := range inputs {
+		fields = append(fields, validatePlaylist(fmt.Sprintf("[%d].", i), in.Name, in.Songs)...)
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+
+	return nil
+}
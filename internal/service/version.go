@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrVersionMismatch = errors.New("playlist version does not match")
+
+func (s *Service) LockPlaylist(id uint) (func(), error) {
+	if _, err := s.GetPlaylist(id); err != nil {
+		return nil, err
+	}
+
+	s.editMu.Lock()
+	mu, ok := s.editLocks[id]
+	if !ok {
+		mu = &sync.Mutex{}
+		s.editLocks[id] = mu
+	}
+	s.editMu.Unlock()
+
+	mu.Lock()
+
+	return mu.Unlock, nil
+}
+
+func (s *Service) LockVersion(id uint, expected uint) (func(), error) {
+	pl, err := s.GetPlaylist(id)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := s.LockPlaylist(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if pl.Version() != expected {
+		unlock()
+
+		return nil, ErrVersionMismatch
+	}
+
+	return unlock, nil
+}
+
+func (s *Service) removeEditLock(id uint) {
+	s.editMu.Lock()
+	defer s.editMu.Unlock()
+
+	delete(s.editLocks, id)
+}
+
+func (s *Service) BumpVersion(ctx context.Context, id uint) error {
+	pl, err := s.GetPlaylist(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.BumpVersion(ctx, id); err != nil {
+		return err
+	}
+
+	pl.BumpVersion()
+
+	return nil
+}
+
+func (s *Service) setVersion(id uint, version uint) {
+	if pl, err := s.GetPlaylist(id); err == nil {
+		pl.SetVersion(version)
+	}
+}
+
+func (s *Service) bumpVersion(id uint) {
+	if pl, err := s.GetPlaylist(id); err == nil {
+		pl.BumpVersion()
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gocloudcamp_test/internal/database"
+)
+
+func TestMutationsBumpVersion(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		mutate func(s *Service, id uint, ids []uint) error
+	}{
+		{"edit playlist", func(s *Service, id uint, ids []uint) error {
+			return s.EditPlaylist(ctx, id, "renamed")
+		}},
+		{"set volume", func(s *Service, id uint, ids []uint) error {
+			return s.SetVolume(ctx, id, 50)
+		}},
+		{"create song", func(s *Service, id uint, ids []uint) error {
+			return s.CreateSong(ctx, &database.Song{PlaylistId: id, Name: "song", Duration: 10})
+		}},
+		{"insert song", func(s *Service, id uint, ids []uint) error {
+			return s.InsertSong(ctx, id, &database.Song{Name: "song", Duration: 10}, 0)
+		}},
+		{"add songs", func(s *Service, id uint, ids []uint) error {
+			return s.AddSongs(ctx, id, []database.Song{{Name: "song", Duration: 10}}, -1)
+		}},
+		{"edit song", func(s *Service, id uint, ids []uint) error {
+			return s.EditSong(ctx, id, ids[0], "renamed", 0, nil)
+		}},
+		{"delete song", func(s *Service, id uint, ids []uint) error {
+			return s.DeleteSong(ctx, id, ids[0])
+		}},
+		{"move song", func(s *Service, id uint, ids []uint) error {
+			return s.MoveSong(ctx, id, ids[0], 2)
+		}},
+		{"swap songs", func(s *Service, id uint, ids []uint) error {
+			return s.SwapSongs(ctx, id, ids[0], ids[1])
+		}},
+		{"replace songs", func(s *Service, id uint, ids []uint) error {
+			return s.ReplaceSongs(ctx, id, []database.Song{{Name: "song", Duration: 10}})
+		}},
+		{"favorite song", func(s *Service, id uint, ids []uint) error {
+			return s.SetFavorite(ctx, id, ids[0], true)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestService(t, Config{})
+			pl := createTestPlaylist(t, s, "playlist", 10, 20, 30)
+
+			before := pl.Version()
+
+			if err := tt.mutate(s, pl.Id, songIds(pl)); err != nil {
+				t.Fatal(err)
+			}
+
+			if after := pl.Version(); after != before+1 {
+				t.Fatalf("version %d after mutation, want %d", after, before+1)
+			}
+		})
+	}
+}
+
+func TestTransferSongBumpsBothVersions(t *testing.T) {
+	s, _ := newTestService(t, Config{})
+	from := createTestPlaylist(t, s, "from", 10, 20)
+	to := createTestPlaylist(t, s, "to", 30)
+
+	fromVersion, toVersion := from.Version(), to.Version()
+
+	if err := s.TransferSong(context.Background(), from.Id, songIds(from)[0], to.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if from.Version() != fromVersion+1 || to.Version() != toVersion+1 {
+		t.Fatalf("versions %d and %d, want %d and %d", from.Version(), to.Version(), fromVersion+1, toVersion+1)
+	}
+}
+
+func TestLockVersion(t *testing.T) {
+	s, _ := newTestService(t, Config{})
+	pl := createTestPlaylist(t, s, "playlist", 10)
+
+	unlock, err := s.LockVersion(pl.Id, pl.Version())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unlock()
+
+	if err := s.EditPlaylist(context.Background(), pl.Id, "renamed"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.LockVersion(pl.Id, pl.Version()-1); !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("stale version: err %v, want %v", err, ErrVersionMismatch)
+	}
+}
+
+func TestDeletePlaylistRemovesEditLock(t *testing.T) {
+	s, _ := newTestService(t, Config{})
+	pl := createTestPlaylist(t, s, "playlist", 10)
+
+	unlock, err := s.LockPlaylist(pl.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unlock()
+
+	if err := s.DeletePlaylist(context.Background(), pl.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	s.editMu.Lock()
+	_, ok := s.editLocks[pl.Id]
+	s.editMu.Unlock()
+
+	if ok {
+		t.Fatal("edit lock kept after the playlist was deleted")
+	}
+
+	if _, err := s.LockPlaylist(pl.Id); !errors.Is(err, ErrPlaylistNotFound) {
+		t.Fatalf("lock deleted playlist: err %v, want %v", err, ErrPlaylistNotFound)
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"errors"
+)
+
+const maxVolume = 100
+
+var ErrInvalidVolume = errors.New("volume must be between 0 and 100")
+
+func ValidateVolume(volume int) error {
+	if volume < 0 || volume > maxVolume {
+		return ErrInvalidVolume
+	}
+
+	return nil
+}
+
+func (s *Service) SetVolume(ctx context.Context, id uint, volume int) error {
+	if err := ValidateVolume(volume); err != nil {
+		return err
+	}
+
+	pl, err := s.GetPlaylist(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.SetVolume(ctx, id, uint(volume)); err != nil {
+		return err
+	}
+
+	pl.SetVolume(uint(volume))
+	pl.BumpVersion()
+
+	return nil
+}
+
+func (s *Service) setVolume(id uint, volume uint) {
+	if pl, err := s.GetPlaylist(id); err == nil {
+		pl.SetVolume(volume)
+	}
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"gocloudcamp_test/internal/playlist"
+)
+
+const (
+	webhookTimeout = time.Second * 5
+	webhookRetries = 3
+	webhookBackoff = time.Millisecond * 500
+)
+
+var ErrInvalidWebhook = errors.New("webhook url must be an absolute http or https url")
+
+type WebhookPayload struct {
+	PlaylistId uint           `json:"playlist_id"`
+	Event      playlist.Event `json:"event"`
+	SongId     uint           `json:"song_id,omitempty"`
+	SongName   string         `json:"song_name,omitempty"`
+	Timestamp  time.Time      `json:"timestamp"`
+}
+
+func ValidateWebhook(raw string) error {
+	if raw == "" {
+		return nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidWebhook
+	}
+
+	return nil
+}
+
+func (s *Service) SetWebhook(ctx context.Context, id uint, raw string) error {
+	if err := ValidateWebhook(raw); err != nil {
+		return err
+	}
+
+	pl, err := s.GetPlaylist(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.SetWebhook(ctx, id, raw); err != nil {
+		return err
+	}
+
+	s.setWebhook(id, raw)
+	pl.BumpVersion()
+
+	return nil
+}
+
+func (s *Service) setWebhook(id uint, raw string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if raw == "" {
+		delete(s.webhooks, id)
+
+		return
+	}
+
+	s.webhooks[id] = raw
+}
+
+func (s *Service) webhook(id uint) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.webhooks[id]
+}
+
+func (s *Service) notifyWebhook(pl *playlist.Playlist, ev playlist.Event) {
+	switch ev {
+	case playlist.EventSwitch, playlist.EventNext, playlist.EventPrev, playlist.EventJump, playlist.EventRestart, playlist.EventStop:
+	default:
+		return
+	}
+
+	target := s.webhook(pl.Id)
+	if target == "" {
+		return
+	}
+
+	st := pl.Status()
+
+	body, err := json.Marshal(WebhookPayload{
+		PlaylistId: st.Id,
+		Event:      ev,
+		SongId:     st.CurrentId,
+		SongName:   st.CurrentName,
+		Timestamp:  time.Now().UTC(),
+	})
+	if err != nil {
+		s.LogError("webhook", err, slog.Uint64("playlist_id", uint64(st.Id)))
+
+		return
+	}
+
+	go s.deliverWebhook(st.Id, target, body)
+}
+
+func (s *Service) deliverWebhook(id uint, target string, body []byte) {
+	var err error
+
+	for attempt := 1; attempt <= webhookRetries; attempt++ {
+		if err = s.postWebhook(target, body); err == nil {
+			log.Printf("service | webhook | id %d | attempt %d", id, attempt)
+
+			return
+		}
+
+		time.Sleep(webhookBackoff * time.Duration(attempt))
+	}
+
+	s.LogError("webhook", err, slog.Uint64("playlist_id", uint64(id)), slog.String("url", target))
+}
+
+func (s *Service) postWebhook(target string, body []byte) error {
+	resp, err := s.webhookClient.Post(target, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
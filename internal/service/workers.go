@@ -0,0 +1,58 @@
+package service
+
+import (
+	"runtime"
+	"sort"
+	"time"
+
+	"gocloudcamp_test/internal/playlist"
+)
+
+type WorkerStatus struct {
+	PlaylistId uint           `json:"playlist_id" xml:"playlist_id"`
+	Name       string         `json:"name" xml:"name"`
+	State      playlist.State `json:"state" xml:"state"`
+	CurrentId  uint           `json:"current_id,omitempty" xml:"current_id,omitempty"`
+	Time       uint           `json:"time" xml:"time"`
+	StartedAt  time.Time      `json:"started_at" xml:"started_at"`
+}
+
+type Workers struct {
+	Launched    int            `json:"launched" xml:"launched"`
+	MaxLaunches int            `json:"max_launches,omitempty" xml:"max_launches,omitempty"`
+	Goroutines  int            `json:"goroutines" xml:"goroutines"`
+	Workers     []WorkerStatus `json:"workers" xml:"workers>worker"`
+}
+
+func (s *Service) Workers() *Workers {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ws := &Workers{
+		Launched:    len(s.workers),
+		MaxLaunches: s.config.MaxLaunches,
+		Goroutines:  runtime.NumGoroutine(),
+		Workers:     make([]WorkerStatus, 0, len(s.workers)),
+	}
+
+	for id, w := range s.workers {
+		status := WorkerStatus{PlaylistId: id, StartedAt: w.started}
+
+		if pl, ok := s.playlists[id]; ok {
+			st := pl.Status()
+
+			status.Name = st.Name
+			status.State = st.State()
+			status.CurrentId = st.CurrentId
+			status.Time = st.Time
+		}
+
+		ws.Workers = append(ws.Workers, status)
+	}
+
+	sort.Slice(ws.Workers, func(i, j int) bool {
+		return ws.Workers[i].PlaylistId < ws.Workers[j].PlaylistId
+	})
+
+	return ws
+}